@@ -0,0 +1,144 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Envelope wraps a value delivered by WithAck.
+// The consumer must call Ack once it has processed Value
+type Envelope[T any] struct {
+	Value T
+	Ack   func()
+}
+
+// WithAck delivers the values of in with at-least-once semantics.
+// A value whose envelope isn't acknowledged within timeout is delivered again,
+// up to maxDeliveries times in total, after which it is dropped so a poisoned value
+// can't loop forever. A maxDeliveries smaller than 1 is treated as 1.
+// Pending redeliveries are served before new values are read from in.
+// Closes once in is closed and every value was either acknowledged or dropped
+func WithAck[T any](ctx context.Context, in <-chan T, timeout time.Duration, maxDeliveries int) <-chan Envelope[T] {
+	if maxDeliveries < 1 {
+		maxDeliveries = 1
+	}
+	envelopes := make(chan Envelope[T])
+
+	go func() {
+		defer close(envelopes)
+
+		clock := clockFrom(ctx)
+		done := make(chan struct{})
+		defer close(done)
+
+		acks := make(chan *delivery[T])
+		expired := make(chan deliveryAttempt[T])
+
+		var (
+			queue       []*delivery[T]
+			outstanding int
+		)
+		source := in
+		for source != nil || outstanding > 0 || len(queue) > 0 {
+			var (
+				out  chan<- Envelope[T]
+				next Envelope[T]
+				recv = source
+			)
+			if len(queue) > 0 {
+				out, recv = envelopes, nil
+				next = Envelope[T]{Value: queue[0].value, Ack: queue[0].ack}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-recv:
+				if !ok {
+					source = nil
+					continue
+				}
+				d := &delivery[T]{value: val}
+				d.ack = func() {
+					d.once.Do(func() {
+						select {
+						case acks <- d:
+						case <-done:
+						}
+					})
+				}
+				queue = append(queue, d)
+			case out <- next:
+				d := queue[0]
+				queue = queue[1:]
+
+				d.attempts++
+				d.inflight = true
+				outstanding++
+
+				attempt := deliveryAttempt[T]{delivery: d, n: d.attempts}
+				timer, acked := clock.NewTimer(timeout), make(chan struct{})
+				d.stop = func() {
+					timer.Stop()
+					close(acked)
+				}
+				go func() {
+					select {
+					case <-timer.C():
+					case <-acked:
+						return
+					case <-done:
+						return
+					}
+					select {
+					case expired <- attempt:
+					case <-done:
+					}
+				}()
+			case d := <-acks:
+				d.acked = true
+				if d.inflight {
+					d.inflight = false
+					d.stop()
+					outstanding--
+				}
+			case a := <-expired:
+				d := a.delivery
+				if !d.inflight || a.n != d.attempts {
+					continue
+				}
+				d.inflight = false
+				outstanding--
+
+				if d.attempts < maxDeliveries {
+					queue = append(queue, d)
+				}
+			}
+
+			// values acknowledged after expiring don't need their redelivery anymore
+			for len(queue) > 0 && queue[0].acked {
+				queue = queue[1:]
+			}
+		}
+	}()
+	return envelopes
+}
+
+// delivery tracks a value of WithAck across its delivery attempts.
+// Only the WithAck goroutine reads and writes its fields, except for once
+type delivery[T any] struct {
+	value    T
+	ack      func()
+	once     sync.Once
+	attempts int
+	inflight bool
+	acked    bool
+	stop     func()
+}
+
+// deliveryAttempt identifies the attempt whose acknowledgement timed out
+type deliveryAttempt[T any] struct {
+	delivery *delivery[T]
+	n        int
+}
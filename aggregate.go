@@ -0,0 +1,229 @@
+package gopatterns
+
+import (
+	"context"
+	"errors"
+)
+
+// Frequency drains in and counts the occurrences of each distinct value.
+// Returns the counts gathered so far if ctx is done
+func Frequency[T comparable](ctx context.Context, in <-chan T) map[T]int {
+	return FrequencyBy(ctx, in, func(v T) T { return v })
+}
+
+// FrequencyBy drains in and counts the values sharing the same key
+func FrequencyBy[T any, K comparable](ctx context.Context, in <-chan T, key func(T) K) map[K]int {
+	counts := make(map[K]int)
+
+	for val := range OrDone(ctx, in) {
+		counts[key(val)]++
+	}
+
+	return counts
+}
+
+// TakeLast drains in and returns its last n values in arrival order.
+// Returns the values buffered so far if ctx is done
+func TakeLast[T any](ctx context.Context, in <-chan T, n int) []T {
+	if n <= 0 {
+		for range OrDone(ctx, in) {
+		}
+		return nil
+	}
+
+	ring := make([]T, 0, n)
+	next := 0
+	for val := range OrDone(ctx, in) {
+		if len(ring) < n {
+			ring = append(ring, val)
+			continue
+		}
+		ring[next] = val
+		next = (next + 1) % n
+	}
+
+	return append(ring[next:], ring[:next]...)
+}
+
+// ToSliceE drains in into a slice.
+// Returns nil once in closes, or the values gathered so far and ctx.Err() if ctx is done first
+func ToSliceE[T any](ctx context.Context, in <-chan T) ([]T, error) {
+	var values []T
+	for {
+		select {
+		case <-ctx.Done():
+			return values, ctx.Err()
+		case val, ok := <-in:
+			if !ok {
+				return values, nil
+			}
+			values = append(values, val)
+		}
+	}
+}
+
+// DrainE discards the values of in until it closes.
+// Returns ctx.Err() if ctx is done first
+func DrainE[T any](ctx context.Context, in <-chan T) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-in:
+			if !ok {
+				return nil
+			}
+		}
+	}
+}
+
+// IsCancelled reports whether err means a terminal stopped because its context was done,
+// as opposed to the stream completing
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Subscribe consumes in on a new goroutine, calling onNext for each value.
+// onDone is then called once, with nil if in closed or ctx.Err() if ctx is done first.
+// onNext runs on the consuming goroutine: as long as it blocks no other value is received,
+// applying backpressure to the upstream
+func Subscribe[T any](ctx context.Context, in <-chan T, onNext func(T), onDone func(error)) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				onDone(ctx.Err())
+				return
+			case val, ok := <-in:
+				if !ok {
+					onDone(nil)
+					return
+				}
+				onNext(val)
+			}
+		}
+	}()
+}
+
+// ForEach calls fn with each value of in and its zero-based index.
+// Returns nil once in closes, ctx.Err() if ctx is done first,
+// or the first error of fn, after which in isn't consumed anymore and cancel is called.
+// cancel is expected to cancel the context of the upstream stages, releasing them.
+// A nil cancel is allowed, for an upstream that doesn't need releasing
+func ForEach[T any](ctx context.Context, cancel context.CancelFunc, in <-chan T, fn func(index int, value T) error) error {
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case val, ok := <-in:
+			if !ok {
+				return nil
+			}
+			if err := fn(i, val); err != nil {
+				if cancel != nil {
+					cancel()
+				}
+				return err
+			}
+		}
+	}
+}
+
+// Conflate counts the occurrences of each distinct value of in and, each time flush fires,
+// emits every value seen since the previous flush with its count, in the order they were first seen.
+// The last tallies are emitted when in closes
+func Conflate[T comparable](ctx context.Context, in <-chan T, flush <-chan struct{}) <-chan Pair[T, int] {
+	tallies := make(chan Pair[T, int])
+
+	go func() {
+		defer close(tallies)
+
+		var (
+			order  []T
+			counts = make(map[T]int)
+		)
+		emit := func() bool {
+			for _, v := range order {
+				select {
+				case <-ctx.Done():
+					return false
+				case tallies <- Pair[T, int]{First: v, Second: counts[v]}:
+				}
+			}
+			order, counts = nil, make(map[T]int)
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-flush:
+				if !ok {
+					flush = nil
+					continue
+				}
+				if !emit() {
+					return
+				}
+			case val, ok := <-in:
+				if !ok {
+					emit()
+					return
+				}
+				if counts[val] == 0 {
+					order = append(order, val)
+				}
+				counts[val]++
+			}
+		}
+	}()
+	return tallies
+}
+
+// Summarize forwards the values of in unchanged on values, folding them into a summary starting from initial.
+// The summary is sent exactly once on summary when in closes, then summary closes.
+// It's buffered so values closes without waiting for the summary to be received.
+// If ctx is done first, summary closes without a value
+func Summarize[T, S any](ctx context.Context, in <-chan T, initial S, fold func(S, T) S) (values <-chan T, summary <-chan S) {
+	forwarded := make(chan T)
+	folded := make(chan S, 1)
+
+	go func() {
+		defer close(folded)
+		defer close(forwarded)
+
+		acc := initial
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					folded <- acc
+					return
+				}
+				acc = fold(acc, val)
+
+				select {
+				case <-ctx.Done():
+					return
+				case forwarded <- val:
+				}
+			}
+		}
+	}()
+	return forwarded, folded
+}
+
+// FoldCount drains in, folding its values with fn starting from initial.
+// Returns the result along with the number of values folded,
+// or the partial result and count so far if ctx is done
+func FoldCount[T, U any](ctx context.Context, in <-chan T, initial U, fn func(U, T) U) (U, int) {
+	acc, n := initial, 0
+	for val := range OrDone(ctx, in) {
+		acc = fn(acc, val)
+		n++
+	}
+	return acc, n
+}
@@ -0,0 +1,534 @@
+package gopatterns
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// SplitWhen groups the values of in into batches,
+// starting a new batch with each value for which isBoundary returns true.
+// The batch in progress is emitted when a boundary arrives and when in closes
+func SplitWhen[T any](ctx context.Context, in <-chan T, isBoundary func(T) bool) <-chan []T {
+	batches := make(chan []T)
+
+	go func() {
+		defer close(batches)
+
+		var batch []T
+		for val := range OrDone(ctx, in) {
+			if isBoundary(val) && len(batch) > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case batches <- batch:
+				}
+				batch = nil
+			}
+			batch = append(batch, val)
+		}
+
+		if len(batch) > 0 && ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case batches <- batch:
+			}
+		}
+	}()
+	return batches
+}
+
+// Coalesce merges the consecutive values of in for which canMerge holds,
+// accumulating them with merge.
+// The accumulated value is emitted when a value that can't be merged arrives, and when in closes
+func Coalesce[T any](ctx context.Context, in <-chan T, canMerge func(a, b T) bool, merge func(a, b T) T) <-chan T {
+	coalesced := make(chan T)
+
+	go func() {
+		defer close(coalesced)
+
+		var (
+			acc     T
+			pending bool
+		)
+		for val := range OrDone(ctx, in) {
+			if !pending {
+				acc, pending = val, true
+				continue
+			}
+			if canMerge(acc, val) {
+				acc = merge(acc, val)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case coalesced <- acc:
+			}
+			acc = val
+		}
+
+		if pending && ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case coalesced <- acc:
+			}
+		}
+	}()
+	return coalesced
+}
+
+// BatchMap groups the values of in by size, calls fn once per batch and emits each of its results.
+// The last, possibly smaller, batch is processed when in closes.
+// A size smaller than 1 is treated as 1
+func BatchMap[T, U any](ctx context.Context, in <-chan T, size int, fn func([]T) []U) <-chan U {
+	if size < 1 {
+		size = 1
+	}
+	results := make(chan U)
+
+	go func() {
+		defer close(results)
+
+		process := func(batch []T) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			for _, res := range fn(batch) {
+				select {
+				case <-ctx.Done():
+					return false
+				case results <- res:
+				}
+			}
+			return true
+		}
+
+		batch := make([]T, 0, size)
+		for val := range OrDone(ctx, in) {
+			batch = append(batch, val)
+			if len(batch) < size {
+				continue
+			}
+			if !process(batch) {
+				return
+			}
+			batch = make([]T, 0, size)
+		}
+
+		if len(batch) > 0 {
+			process(batch)
+		}
+	}()
+	return results
+}
+
+// BufferUntil accumulates the values of in and emits the batch each time trigger fires,
+// then starts a new one. Nothing is emitted when the batch is empty.
+// The last batch is emitted when in closes
+func BufferUntil[T any](ctx context.Context, in <-chan T, trigger <-chan struct{}) <-chan []T {
+	batches := make(chan []T)
+
+	go func() {
+		defer close(batches)
+
+		var batch []T
+		emit := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case batches <- batch:
+				batch = nil
+				return true
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-trigger:
+				if !ok {
+					trigger = nil
+					continue
+				}
+				if !emit() {
+					return
+				}
+			case val, ok := <-in:
+				if !ok {
+					emit()
+					return
+				}
+				batch = append(batch, val)
+			}
+		}
+	}()
+	return batches
+}
+
+// MicroBatch groups the values of in into batches flushed once they hold maxSize values,
+// but not before minDelay after their first value, giving them time to grow,
+// and no later than maxDelay after it.
+// A full batch waiting for minDelay stops reading from in.
+// The last batch is flushed when in closes
+func MicroBatch[T any](ctx context.Context, in <-chan T, maxSize int, maxDelay time.Duration, minDelay time.Duration) <-chan []T {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	if minDelay > maxDelay {
+		minDelay = maxDelay
+	}
+	batches := make(chan []T)
+
+	go func() {
+		defer close(batches)
+
+		clock := clockFrom(ctx)
+		var (
+			batch       []T
+			minTimer    Timer
+			maxTimer    Timer
+			minC, maxC  <-chan time.Time
+			grownEnough bool
+		)
+		stopTimers := func() {
+			if minTimer != nil {
+				minTimer.Stop()
+				maxTimer.Stop()
+			}
+			minC, maxC = nil, nil
+		}
+		defer stopTimers()
+
+		flush := func() bool {
+			stopTimers()
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case batches <- batch:
+				batch, grownEnough = nil, false
+				return true
+			}
+		}
+
+		for {
+			recv := in
+			if len(batch) >= maxSize {
+				recv = nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-recv:
+				if !ok {
+					flush()
+					return
+				}
+				if len(batch) == 0 {
+					minTimer, maxTimer = clock.NewTimer(minDelay), clock.NewTimer(maxDelay)
+					minC, maxC = minTimer.C(), maxTimer.C()
+				}
+				batch = append(batch, val)
+				if len(batch) >= maxSize && grownEnough && !flush() {
+					return
+				}
+			case <-minC:
+				minC, grownEnough = nil, true
+				if len(batch) >= maxSize && !flush() {
+					return
+				}
+			case <-maxC:
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return batches
+}
+
+// WindowToggle collects the values of in arriving between a signal on open and a signal on closeSig,
+// emitting each window's values, possibly none, when closeSig fires.
+// Values arriving while no window is open are dropped.
+// Windows don't overlap: open firing while a window is already open is ignored,
+// as is closeSig firing while none is.
+// A window still open when in closes is emitted
+func WindowToggle[T any](ctx context.Context, in <-chan T, open <-chan struct{}, closeSig <-chan struct{}) <-chan []T {
+	windows := make(chan []T)
+
+	go func() {
+		defer close(windows)
+
+		var window []T
+		emit := func() bool {
+			defer func() { window = nil }()
+			select {
+			case <-ctx.Done():
+				return false
+			case windows <- window:
+				return true
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-open:
+				if !ok {
+					open = nil
+					continue
+				}
+				if window == nil {
+					window = []T{}
+				}
+			case _, ok := <-closeSig:
+				if !ok {
+					closeSig = nil
+					continue
+				}
+				if window != nil && !emit() {
+					return
+				}
+			case val, ok := <-in:
+				if !ok {
+					if window != nil {
+						emit()
+					}
+					return
+				}
+				if window != nil {
+					window = append(window, val)
+				}
+			}
+		}
+	}()
+	return windows
+}
+
+// ChunkBytes groups the values of in into batches whose total sizeFn stays within maxBytes:
+// a value that would overflow the batch starts the next one instead.
+// A value larger than maxBytes on its own gets a batch of its own.
+// The last batch is emitted when in closes
+func ChunkBytes[T any](ctx context.Context, in <-chan T, sizeFn func(T) int, maxBytes int) <-chan []T {
+	return chunkBytes(ctx, in, sizeFn, maxBytes, false)
+}
+
+// ChunkBytesInclusive is ChunkBytes keeping the overflowing value in the batch it overflows,
+// emitting the batch as soon as its total sizeFn exceeds maxBytes
+func ChunkBytesInclusive[T any](ctx context.Context, in <-chan T, sizeFn func(T) int, maxBytes int) <-chan []T {
+	return chunkBytes(ctx, in, sizeFn, maxBytes, true)
+}
+
+func chunkBytes[T any](ctx context.Context, in <-chan T, sizeFn func(T) int, maxBytes int, inclusive bool) <-chan []T {
+	batches := make(chan []T)
+
+	go func() {
+		defer close(batches)
+
+		var (
+			batch []T
+			total int
+		)
+		emit := func() bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case batches <- batch:
+				batch, total = nil, 0
+				return true
+			}
+		}
+
+		for val := range OrDone(ctx, in) {
+			size := sizeFn(val)
+			if !inclusive && len(batch) > 0 && total+size > maxBytes && !emit() {
+				return
+			}
+
+			batch = append(batch, val)
+			total += size
+
+			if inclusive && total > maxBytes && !emit() {
+				return
+			}
+		}
+
+		if len(batch) > 0 && ctx.Err() == nil {
+			emit()
+		}
+	}()
+	return batches
+}
+
+// AdaptiveBatch groups the values of in into batches whose size is tuned between minSize and maxSize
+// to keep the latency of each batch, from its first value until its consumer received it, near targetLatency.
+// A batch is flushed once it reaches the current size, or targetLatency after its first value.
+// After each flush the size is scaled by targetLatency over the observed latency,
+// at most halving or doubling it at once.
+// The sizes are clamped to at least 1, and the last batch is flushed when in closes
+func AdaptiveBatch[T any](ctx context.Context, in <-chan T, minSize, maxSize int, targetLatency time.Duration) <-chan []T {
+	batches, _ := AdaptiveBatchObserved(ctx, in, minSize, maxSize, targetLatency)
+	return batches
+}
+
+// AdaptiveBatchObserved is AdaptiveBatch also returning a function reporting the current batch size
+func AdaptiveBatchObserved[T any](ctx context.Context, in <-chan T, minSize, maxSize int, targetLatency time.Duration) (<-chan []T, func() int) {
+	if minSize < 1 {
+		minSize = 1
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	batches := make(chan []T)
+	size := new(atomic.Int64)
+	size.Store(int64(minSize))
+
+	go func() {
+		defer close(batches)
+
+		clock := clockFrom(ctx)
+		var (
+			batch   []T
+			started time.Time
+			timer   Timer
+			timeout <-chan time.Time
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+			}
+			timeout = nil
+			if len(batch) == 0 {
+				return true
+			}
+
+			select {
+			case <-ctx.Done():
+				return false
+			case batches <- batch:
+			}
+			batch = nil
+
+			latency := clock.Now().Sub(started)
+			current := int(size.Load())
+			next := current * 2
+			if latency > 0 {
+				next = int(float64(current) * float64(targetLatency) / float64(latency))
+			}
+			if next > current*2 {
+				next = current * 2
+			}
+			if next < current/2 {
+				next = current / 2
+			}
+			if next < minSize {
+				next = minSize
+			}
+			if next > maxSize {
+				next = maxSize
+			}
+			size.Store(int64(next))
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				if len(batch) == 0 {
+					started = clock.Now()
+					if timer == nil {
+						timer = clock.NewTimer(targetLatency)
+					} else {
+						resetTimer(timer, targetLatency)
+					}
+					timeout = timer.C()
+				}
+				batch = append(batch, val)
+				if len(batch) >= int(size.Load()) && !flush() {
+					return
+				}
+			case <-timeout:
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return batches, func() int { return int(size.Load()) }
+}
+
+// ReduceWindow folds the values of in arriving within each interval into an aggregate
+// starting from a fresh initial(), emitting it at the end of the interval.
+// Intervals without values emit nothing.
+// The last, partial, aggregate is emitted when in closes.
+// Panics if interval isn't positive, like time.NewTicker
+func ReduceWindow[T, U any](ctx context.Context, in <-chan T, interval time.Duration, initial func() U, fold func(U, T) U) <-chan U {
+	aggregates := make(chan U)
+
+	go func() {
+		defer close(aggregates)
+
+		ticker := clockFrom(ctx).NewTicker(interval)
+		defer ticker.Stop()
+
+		var (
+			acc  U
+			seen bool
+		)
+		emit := func() bool {
+			if !seen {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case aggregates <- acc:
+				seen = false
+				return true
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					emit()
+					return
+				}
+				if !seen {
+					acc, seen = initial(), true
+				}
+				acc = fold(acc, val)
+			case <-ticker.C():
+				if !emit() {
+					return
+				}
+			}
+		}
+	}()
+	return aggregates
+}
@@ -0,0 +1,156 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+)
+
+// ReplayBroker publishes values to its subscribers, each new subscriber
+// first receiving the last published values before the live ones.
+// Each subscriber queues the values it didn't receive yet, so a slow subscriber
+// never blocks Publish but buffers without bound.
+// It's safe for concurrent use
+type ReplayBroker[T any] struct {
+	mu      sync.Mutex
+	history *Ring[T]
+	subs    map[*replaySub[T]]struct{}
+	closed  bool
+}
+
+// NewReplayBroker creates a ReplayBroker replaying up to history values to new subscribers.
+// A history smaller than 1 replays nothing
+func NewReplayBroker[T any](history int) *ReplayBroker[T] {
+	b := &ReplayBroker[T]{subs: make(map[*replaySub[T]]struct{})}
+	if history > 0 {
+		b.history = NewRing[T](history)
+	}
+	return b
+}
+
+// Publish sends v to every subscriber and records it in the history.
+// Values published after Close are ignored
+func (b *ReplayBroker[T]) Publish(v T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	if b.history != nil {
+		b.history.Push(v)
+	}
+	for sub := range b.subs {
+		sub.push(v)
+	}
+}
+
+// Subscribe returns a channel receiving the history, then every value published afterward.
+// It closes once ctx is done, or after the queued values were received once the broker is closed
+func (b *ReplayBroker[T]) Subscribe(ctx context.Context) <-chan T {
+	sub := &replaySub[T]{wake: make(chan struct{}, 1)}
+
+	b.mu.Lock()
+	if b.history != nil {
+		sub.queue = b.history.Snapshot()
+	}
+	if b.closed {
+		sub.closed = true
+	} else {
+		b.subs[sub] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	values := make(chan T)
+	go func() {
+		defer close(values)
+		defer b.unsubscribe(sub)
+
+		for {
+			v, ok, closed := sub.pop()
+			if closed {
+				return
+			}
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return
+				case <-sub.wake:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- v:
+			}
+		}
+	}()
+	return values
+}
+
+// Close stops the broker, closing the subscribers once they received their queued values
+func (b *ReplayBroker[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		sub.close()
+	}
+	b.subs = nil
+}
+
+func (b *ReplayBroker[T]) unsubscribe(sub *replaySub[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, sub)
+}
+
+// replaySub is the queue of values a subscriber didn't receive yet
+type replaySub[T any] struct {
+	mu     sync.Mutex
+	queue  []T
+	closed bool
+	wake   chan struct{}
+}
+
+func (s *replaySub[T]) push(v T) {
+	s.mu.Lock()
+	s.queue = append(s.queue, v)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *replaySub[T]) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next queued value, or whether the subscriber is done
+// because the broker closed and nothing is left to receive
+func (s *replaySub[T]) pop() (v T, ok bool, done bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return v, false, s.closed
+	}
+	v = s.queue[0]
+	s.queue = s.queue[1:]
+	return v, true, false
+}
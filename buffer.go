@@ -0,0 +1,113 @@
+package gopatterns
+
+import "context"
+
+// Prefetch reads up to n values of in ahead of the consumer.
+// Unlike a buffered channel, which only fills as fast as the producer pushes into it,
+// Prefetch keeps pulling from in greedily whenever it holds fewer than n values.
+// A n smaller than 1 is treated as 1
+func Prefetch[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	if n < 1 {
+		n = 1
+	}
+	prefetched := make(chan T)
+
+	go func() {
+		defer close(prefetched)
+
+		queue := make([]T, 0, n)
+		for in != nil || len(queue) > 0 {
+			var (
+				recv <-chan T
+				out  chan<- T
+				head T
+			)
+			if len(queue) < n {
+				recv = in
+			}
+			if len(queue) > 0 {
+				out, head = prefetched, queue[0]
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+				queue = append(queue, val)
+			case out <- head:
+				queue = queue[1:]
+			}
+		}
+	}()
+	return prefetched
+}
+
+// Spillover sends the values of in to primary while its buffer of primaryCap values has room,
+// spilling the excess to overflow instead of waiting on the primary consumer.
+// A value that spilled still waits for the overflow consumer.
+// Both channels close when in closes
+func Spillover[T any](ctx context.Context, in <-chan T, primaryCap int) (primary, overflow <-chan T) {
+	if primaryCap < 0 {
+		primaryCap = 0
+	}
+	fast := make(chan T, primaryCap)
+	slow := make(chan T)
+
+	go func() {
+		defer close(fast)
+		defer close(slow)
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case fast <- val:
+				continue
+			default:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case slow <- val:
+			}
+		}
+	}()
+	return fast, slow
+}
+
+// DoubleBuffer accumulates the values of in while the consumer is busy,
+// handing it everything accumulated so far as soon as it's ready to receive, and starting a new buffer.
+// The producer is never held back, so the buffer is unbounded.
+// The last buffer is emitted when in closes
+func DoubleBuffer[T any](ctx context.Context, in <-chan T) <-chan []T {
+	buffers := make(chan []T)
+
+	go func() {
+		defer close(buffers)
+
+		var buf []T
+		for in != nil || len(buf) > 0 {
+			var out chan<- []T
+			if len(buf) > 0 {
+				out = buffers
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				buf = append(buf, val)
+			case out <- buf:
+				buf = nil
+			}
+		}
+	}()
+	return buffers
+}
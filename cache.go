@@ -0,0 +1,89 @@
+package gopatterns
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// CacheMap applies fn to every key of in, caching up to capacity results.
+// Once full the least recently used result is evicted.
+// A capacity smaller than 1 is treated as 1
+func CacheMap[K comparable, U any](ctx context.Context, in <-chan K, capacity int, fn func(K) U) <-chan U {
+	cache := newLRU[K, U](capacity)
+	results := make(chan U)
+
+	go func() {
+		defer close(results)
+
+		for key := range OrDone(ctx, in) {
+			res, ok := cache.get(key)
+			if !ok {
+				res = fn(key)
+				cache.put(key, res)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
+
+type lruEntry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// lru is a least recently used cache, safe for concurrent use
+type lru[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[K]*list.Element
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lru[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element, capacity),
+	}
+}
+
+func (c *lru[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(lruEntry[K, V]).val, true
+}
+
+func (c *lru[K, V]) put(key K, val V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value = lruEntry[K, V]{key: key, val: val}
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(lruEntry[K, V]{key: key, val: val})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(lruEntry[K, V]).key)
+	}
+}
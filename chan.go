@@ -0,0 +1,90 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// TryTake receives from in without blocking.
+// Returns false if no value is immediately available or in is closed
+func TryTake[T any](in <-chan T) (T, bool) {
+	select {
+	case v, ok := <-in:
+		return v, ok
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
+// TrySend sends v on out without blocking.
+// Returns false if out isn't immediately ready to receive it
+func TrySend[T any](out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// SafeChan is a channel that can be closed any number of times, from any goroutine.
+// Sending on a closed SafeChan fails instead of panicking
+type SafeChan[T any] struct {
+	c      chan T
+	done   chan struct{}
+	mu     sync.RWMutex
+	once   sync.Once
+	closed atomic.Bool
+}
+
+// NewSafeChan creates a SafeChan buffering up to size values
+func NewSafeChan[T any](size int) *SafeChan[T] {
+	return &SafeChan[T]{c: make(chan T, size), done: make(chan struct{})}
+}
+
+// Send sends v, blocking until it's received or buffered.
+// Returns false if the channel is closed, before or while sending, or if ctx is done first
+func (s *SafeChan[T]) Send(ctx context.Context, v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed.Load() {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.done:
+		return false
+	case s.c <- v:
+		return true
+	}
+}
+
+// Recv receives a value, blocking until one is available.
+// Once closed, the buffered values are still received before it returns false
+func (s *SafeChan[T]) Recv() (T, bool) {
+	v, ok := <-s.c
+	return v, ok
+}
+
+// C returns the underlying channel, to receive from it in a select or pass it to a stage
+func (s *SafeChan[T]) C() <-chan T {
+	return s.c
+}
+
+// Close closes the channel. Calling it again does nothing.
+// The pending Send calls are released and return false
+func (s *SafeChan[T]) Close() {
+	s.once.Do(func() {
+		s.closed.Store(true)
+		close(s.done)
+
+		// wait for the senders in flight to leave before closing
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.c)
+	})
+}
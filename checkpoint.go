@@ -0,0 +1,48 @@
+package gopatterns
+
+import "context"
+
+// Checkpoint forwards the values of in unchanged, calling save with every every-th value
+// once it was forwarded so the progress can be persisted.
+// Stops and closes if save returns an error
+func Checkpoint[T any](ctx context.Context, in <-chan T, every int, save func(T) error) <-chan T {
+	return CheckpointOpts(ctx, in, every, save, nil, true)
+}
+
+// CheckpointOpts is Checkpoint handing the errors of save to onError, if not nil.
+// With stop the stage closes on the first error, otherwise it keeps forwarding.
+// An every smaller than 1 is treated as 1
+func CheckpointOpts[T any](ctx context.Context, in <-chan T, every int, save func(T) error, onError func(error), stop bool) <-chan T {
+	if every < 1 {
+		every = 1
+	}
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		n := 0
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+
+			n++
+			if n%every != 0 {
+				continue
+			}
+
+			if err := save(val); err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				if stop {
+					return
+				}
+			}
+		}
+	}()
+	return values
+}
@@ -0,0 +1,174 @@
+// Package clocktest provides a fake gopatterns.Clock whose time only moves when told to,
+// making the time based stages testable without sleeping
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"gopatterns"
+)
+
+// FakeClock is a gopatterns.Clock advanced manually with Advance.
+// It's safe for concurrent use
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters map[*waiter]struct{}
+	arms    int
+}
+
+// NewFakeClock creates a FakeClock starting at start
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start, waiters: make(map[*waiter]struct{})}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After fires once the clock advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a Timer firing once the clock advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) gopatterns.Timer {
+	w := &waiter{clock: c, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+// NewTicker creates a Ticker firing each time the clock advanced by d.
+// Panics if d isn't positive, like time.NewTicker
+func (c *FakeClock) NewTicker(d time.Duration) gopatterns.Ticker {
+	if d <= 0 {
+		panic("clocktest: non-positive interval for NewTicker")
+	}
+	w := &waiter{clock: c, c: make(chan time.Time, 1), period: d}
+	w.Reset(d)
+	return ticker{w}
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers due in deadline order.
+// Like the real ones, a timer or ticker whose previous event wasn't received drops the new one
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		var next *waiter
+		for w := range c.waiters {
+			if !w.at.After(target) && (next == nil || w.at.Before(next.at)) {
+				next = w
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		c.now = next.at
+		select {
+		case next.c <- c.now:
+		default:
+		}
+
+		if next.period > 0 {
+			next.at = next.at.Add(next.period)
+		} else {
+			delete(c.waiters, next)
+		}
+	}
+	c.now = target
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers and tickers are waiting on the clock.
+// It lets a test wait for a stage to arm its timer before advancing the clock
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// BlockUntilArmed blocks until timers and tickers were armed at least n times in total,
+// counting each creation and each Reset.
+// Unlike BlockUntil, it tells a stage re-arming its timer apart from one still waiting on the previous deadline
+func (c *FakeClock) BlockUntilArmed(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.arms < n {
+		c.cond.Wait()
+	}
+}
+
+// Armed returns how many times timers and tickers were armed so far, counting each creation and each Reset
+func (c *FakeClock) Armed() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.arms
+}
+
+// waiter is a timer, or a ticker when it has a period
+type waiter struct {
+	clock  *FakeClock
+	c      chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+type ticker struct {
+	*waiter
+}
+
+func (t ticker) Stop() {
+	t.waiter.Stop()
+}
+
+func (w *waiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *waiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	_, active := w.clock.waiters[w]
+	delete(w.clock.waiters, w)
+	w.clock.cond.Broadcast()
+	return active
+}
+
+func (w *waiter) Reset(d time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, active := c.waiters[w]
+	c.arms++
+	w.at = c.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		delete(c.waiters, w)
+		select {
+		case w.c <- c.now:
+		default:
+		}
+	} else {
+		c.waiters[w] = struct{}{}
+	}
+	c.cond.Broadcast()
+	return active
+}
@@ -0,0 +1,156 @@
+package gopatterns
+
+import (
+	"context"
+	"time"
+)
+
+// Debounce emits the last value of each burst of in once no value arrived for delay.
+// The pending value is flushed when in closes
+func Debounce[T any](ctx context.Context, in <-chan T, delay time.Duration) <-chan T {
+	return DebounceOpts(ctx, in, delay, false, true)
+}
+
+// DebounceOpts debounces in, a burst ending once no value arrived for delay.
+// With leading the first value of a burst is emitted immediately,
+// with trailing the last value of a burst is emitted once it's over.
+// With both, the trailing value is only emitted if the burst had more than one value.
+// With neither, nothing is ever emitted and the stage only drains in
+func DebounceOpts[T any](ctx context.Context, in <-chan T, delay time.Duration, leading, trailing bool) <-chan T {
+	debounced := make(chan T)
+
+	go func() {
+		defer close(debounced)
+
+		clock := clockFrom(ctx)
+		var (
+			timer   Timer
+			quiet   <-chan time.Time
+			pending T
+			hasNext bool
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		send := func(v T) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case debounced <- v:
+				return true
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					if trailing && hasNext {
+						send(pending)
+					}
+					return
+				}
+
+				if quiet == nil && leading {
+					if !send(val) {
+						return
+					}
+				} else {
+					pending, hasNext = val, true
+				}
+
+				if timer == nil {
+					timer = clock.NewTimer(delay)
+				} else {
+					resetTimer(timer, delay)
+				}
+				quiet = timer.C()
+			case <-quiet:
+				quiet = nil
+				if trailing && hasNext && !send(pending) {
+					return
+				}
+				hasNext = false
+			}
+		}
+	}()
+	return debounced
+}
+
+// DistinctDebounced emits a value of in once it remained unchanged for quiet,
+// and only if it differs from the last value emitted.
+// This suppresses both the duplicates and the transient flickers of a noisy input.
+// A value still settling when in closes is flushed if it differs from the last value emitted
+func DistinctDebounced[T comparable](ctx context.Context, in <-chan T, quiet time.Duration) <-chan T {
+	distinct := make(chan T)
+
+	go func() {
+		defer close(distinct)
+
+		clock := clockFrom(ctx)
+		var (
+			timer     Timer
+			settled   <-chan time.Time
+			candidate T
+			last      T
+			emitted   bool
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		emit := func() bool {
+			settled = nil
+			if emitted && candidate == last {
+				return true
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case distinct <- candidate:
+				last, emitted = candidate, true
+				return true
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					if settled != nil {
+						emit()
+					}
+					return
+				}
+				if settled != nil && val == candidate {
+					continue
+				}
+				if settled == nil && emitted && val == last {
+					continue
+				}
+
+				candidate = val
+				if timer == nil {
+					timer = clock.NewTimer(quiet)
+				} else {
+					resetTimer(timer, quiet)
+				}
+				settled = timer.C()
+			case <-settled:
+				if !emit() {
+					return
+				}
+			}
+		}
+	}()
+	return distinct
+}
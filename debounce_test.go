@@ -0,0 +1,41 @@
+package gopatterns_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gopatterns"
+	"gopatterns/clocktest"
+)
+
+func TestDebounceFakeClock(t *testing.T) {
+	clock := clocktest.NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(gopatterns.WithClock(context.Background(), clock))
+	defer cancel()
+
+	in := make(chan int)
+	out := gopatterns.Debounce(ctx, in, time.Second)
+
+	// each value re-arms the timer, the burst only ending a second after the last one
+	for i, v := range []int{1, 2, 3} {
+		in <- v
+		clock.BlockUntilArmed(i + 1)
+		clock.Advance(500 * time.Millisecond)
+	}
+	select {
+	case v := <-out:
+		t.Fatalf("got %d before the burst ended", v)
+	default:
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if v := <-out; v != 3 {
+		t.Fatalf("got %d, want 3", v)
+	}
+
+	close(in)
+	if v, ok := <-out; ok {
+		t.Fatalf("got %d after in closed", v)
+	}
+}
@@ -0,0 +1,120 @@
+package gopatterns
+
+import "context"
+
+// Transition is a change of value in a stream.
+// Initial is set for the first value, in which case Prev is the zero value
+type Transition[T any] struct {
+	Prev    T
+	Next    T
+	Initial bool
+}
+
+// Changes emits a Transition each time the value received from in differs from the previous one.
+// The first value always emits an initial Transition
+func Changes[T comparable](ctx context.Context, in <-chan T) <-chan Transition[T] {
+	transitions := make(chan Transition[T])
+
+	go func() {
+		defer close(transitions)
+
+		var (
+			prev    T
+			started bool
+		)
+		for val := range OrDone(ctx, in) {
+			if started && val == prev {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case transitions <- Transition[T]{Prev: prev, Next: val, Initial: !started}:
+			}
+			prev, started = val, true
+		}
+	}()
+	return transitions
+}
+
+// DistinctBy forwards the values of in whose key differs from the key of the previously forwarded value.
+// The first value is always forwarded
+func DistinctBy[T any, K comparable](ctx context.Context, in <-chan T, keyFn func(T) K) <-chan T {
+	distinct := make(chan T)
+
+	go func() {
+		defer close(distinct)
+
+		var (
+			last    K
+			started bool
+		)
+		for val := range OrDone(ctx, in) {
+			key := keyFn(val)
+			if started && key == last {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case distinct <- val:
+			}
+			last, started = key, true
+		}
+	}()
+	return distinct
+}
+
+// Latch forwards the first value of in, then only the values differing from the last one forwarded.
+// It's DistinctBy keyed by the value itself: where DistinctBy drops a value
+// whose key matches even if the value changed, Latch forwards any change.
+// Like DistinctBy, the first value is always forwarded, even if it's the zero value
+func Latch[T comparable](ctx context.Context, in <-chan T) <-chan T {
+	return DistinctBy(ctx, in, func(v T) T { return v })
+}
+
+// DedupWindow drops the values of in already seen among the n previous values,
+// duplicates included, so the memory used is bounded by n.
+// A n smaller than 1 forwards every value
+func DedupWindow[T comparable](ctx context.Context, in <-chan T, n int) <-chan T {
+	if n < 1 {
+		return OrDone(ctx, in)
+	}
+	deduped := make(chan T)
+
+	go func() {
+		defer close(deduped)
+
+		var (
+			ring = make([]T, n)
+			seen = make(map[T]int, n)
+			i    int
+		)
+		for val := range OrDone(ctx, in) {
+			dup := seen[val] > 0
+
+			if i >= n {
+				old := ring[i%n]
+				if seen[old]--; seen[old] == 0 {
+					delete(seen, old)
+				}
+			}
+			ring[i%n] = val
+			seen[val]++
+			i++
+
+			if dup {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case deduped <- val:
+			}
+		}
+	}()
+	return deduped
+}
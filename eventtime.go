@@ -0,0 +1,148 @@
+package gopatterns
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// MergeByTime merges channels into an output approximately ordered by the timestamps tsFn returns.
+// Each input is expected to be roughly ordered itself. Values are held until every open input
+// produced a value at least lateness past their timestamp (the watermark), then emitted in order.
+// An input that produced nothing yet holds back the whole output until it emits or closes.
+// Values arriving behind the watermark are late: they are emitted as soon as possible,
+// out of order, rather than dropped.
+// The held values are flushed in order once all inputs are closed
+func MergeByTime[T any](ctx context.Context, tsFn func(T) time.Time, lateness time.Duration, channels ...<-chan T) <-chan T {
+	type event struct {
+		input  int
+		val    T
+		closed bool
+	}
+
+	events := make(chan event)
+	merged := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+
+	for i, c := range channels {
+		go func(i int, c <-chan T) {
+			defer wg.Done()
+			for val := range OrDone(ctx, c) {
+				select {
+				case <-ctx.Done():
+					return
+				case events <- event{input: i, val: val}:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+			case events <- event{input: i, closed: true}:
+			}
+		}(i, c)
+	}
+
+	go func() {
+		defer close(merged)
+		defer wg.Wait()
+
+		var (
+			held    timeHeap[T]
+			seq     int
+			latest  = make([]time.Time, len(channels))
+			seen    = make([]bool, len(channels))
+			open    = make([]bool, len(channels))
+			running = len(channels)
+		)
+		for i := range open {
+			open[i] = true
+		}
+
+		// emit sends the held values up to the watermark, or all of them when flushing
+		emit := func(flush bool) bool {
+			var watermark time.Time
+			if !flush {
+				first := true
+				for i := range channels {
+					if !open[i] {
+						continue
+					}
+					if !seen[i] {
+						return true
+					}
+					if first || latest[i].Before(watermark) {
+						watermark, first = latest[i], false
+					}
+				}
+				watermark = watermark.Add(-lateness)
+			}
+
+			for held.Len() > 0 && (flush || !held[0].ts.After(watermark)) {
+				e := heap.Pop(&held).(timeEntry[T])
+				select {
+				case <-ctx.Done():
+					return false
+				case merged <- e.val:
+				}
+			}
+			return true
+		}
+
+		for running > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-events:
+				if e.closed {
+					open[e.input] = false
+					running--
+				} else {
+					ts := tsFn(e.val)
+					if !seen[e.input] || ts.After(latest[e.input]) {
+						latest[e.input], seen[e.input] = ts, true
+					}
+					heap.Push(&held, timeEntry[T]{ts: ts, seq: seq, val: e.val})
+					seq++
+				}
+
+				if !emit(running == 0) {
+					return
+				}
+			}
+		}
+	}()
+
+	return merged
+}
+
+type timeEntry[T any] struct {
+	ts  time.Time
+	seq int
+	val T
+}
+
+// timeHeap is a min-heap of entries ordered by timestamp, then arrival
+type timeHeap[T any] []timeEntry[T]
+
+func (h timeHeap[T]) Len() int { return len(h) }
+
+func (h timeHeap[T]) Less(i, j int) bool {
+	if h[i].ts.Equal(h[j].ts) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].ts.Before(h[j].ts)
+}
+
+func (h timeHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *timeHeap[T]) Push(x any) { *h = append(*h, x.(timeEntry[T])) }
+
+func (h *timeHeap[T]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
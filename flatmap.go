@@ -0,0 +1,128 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+)
+
+// ConcatMap expands each value of in into the stream fn returns,
+// fully draining it before moving to the next value,
+// so the output keeps the order of in and each expansion stays grouped
+func ConcatMap[T, U any](ctx context.Context, in <-chan T, fn func(context.Context, T) <-chan U) <-chan U {
+	values := make(chan U)
+
+	go func() {
+		defer close(values)
+
+		for val := range OrDone(ctx, in) {
+			for sub := range OrDone(ctx, fn(ctx, val)) {
+				select {
+				case <-ctx.Done():
+					return
+				case values <- sub:
+				}
+			}
+		}
+	}()
+	return values
+}
+
+// MergeMap expands each value of in into the stream fn returns,
+// draining up to concurrency of these streams at once and merging their values.
+// The order of the values isn't preserved.
+// Closes once in and all the streams started are exhausted.
+// A concurrency smaller than 1 is treated as 1
+func MergeMap[T, U any](ctx context.Context, in <-chan T, concurrency int, fn func(context.Context, T) <-chan U) <-chan U {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	values := make(chan U)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer func() {
+			wg.Wait()
+			close(values)
+		}()
+
+		sem := make(chan struct{}, concurrency)
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(val T) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				for sub := range OrDone(ctx, fn(ctx, val)) {
+					select {
+					case <-ctx.Done():
+						return
+					case values <- sub:
+					}
+				}
+			}(val)
+		}
+	}()
+	return values
+}
+
+// SwitchMap expands each value of in into the stream fn returns, only following the latest one:
+// a new value cancels the context given to fn for the previous value and abandons its stream,
+// so only the values of the latest stream reach the output.
+// fn is expected to stop feeding its stream once its context is done.
+// Closes once in and the latest stream are exhausted
+func SwitchMap[T, U any](ctx context.Context, in <-chan T, fn func(context.Context, T) <-chan U) <-chan U {
+	values := make(chan U)
+
+	go func() {
+		defer close(values)
+
+		var (
+			sub     <-chan U
+			cancel  = func() {}
+			pending U
+			has     bool
+		)
+		defer func() { cancel() }()
+
+		for in != nil || sub != nil || has {
+			var (
+				recv <-chan U
+				out  chan<- U
+			)
+			if has {
+				out = values
+			} else {
+				recv = sub
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				cancel()
+				subCtx, subCancel := context.WithCancel(ctx)
+				cancel = subCancel
+				sub, has = fn(subCtx, val), false
+			case v, ok := <-recv:
+				if !ok {
+					sub = nil
+					continue
+				}
+				pending, has = v, true
+			case out <- pending:
+				has = false
+			}
+		}
+	}()
+	return values
+}
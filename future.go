@@ -0,0 +1,169 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the eventual result of an asynchronous call.
+// Await can be called any number of times, from any number of goroutines
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// Async calls fn on a new goroutine and returns the Future of its result
+func Async[T any](ctx context.Context, fn func(context.Context) (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+
+	go func() {
+		defer close(f.done)
+		f.value, f.err = fn(ctx)
+	}()
+	return f
+}
+
+// Done is closed once the result is available
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await blocks until the result is available or ctx is done
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-f.done:
+		return f.value, f.err
+	}
+}
+
+// WaitAll blocks until all the futures complete and returns their values in order.
+// Returns the first error to occur without waiting for the other futures,
+// or ctx.Err() if ctx is done first
+func WaitAll[T any](ctx context.Context, futures ...*Future[T]) ([]T, error) {
+	completed, stop := completions(futures)
+	defer close(stop)
+
+	for range futures {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case i := <-completed:
+			if err := futures[i].err; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	values := make([]T, len(futures))
+	for i, f := range futures {
+		values[i] = f.value
+	}
+	return values, nil
+}
+
+// WaitAny returns the result of the first future to complete,
+// or ctx.Err() if ctx is done first.
+// Without futures it blocks until ctx is done
+func WaitAny[T any](ctx context.Context, futures ...*Future[T]) (T, error) {
+	completed, stop := completions(futures)
+	defer close(stop)
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case i := <-completed:
+		return futures[i].value, futures[i].err
+	}
+}
+
+// completions sends the index of each future as it completes.
+// Closing stop releases the goroutines still waiting
+func completions[T any](futures []*Future[T]) (<-chan int, chan<- struct{}) {
+	completed := make(chan int, len(futures))
+	stop := make(chan struct{})
+
+	for i, f := range futures {
+		go func(i int, f *Future[T]) {
+			select {
+			case <-stop:
+			case <-f.done:
+				completed <- i
+			}
+		}(i, f)
+	}
+	return completed, stop
+}
+
+// SharedFuture is a Future computed lazily, on the first call to Await.
+// However many goroutines await it, fn runs exactly once and they all get its result
+type SharedFuture[T any] struct {
+	ctx    context.Context
+	fn     func(context.Context) (T, error)
+	once   sync.Once
+	future *Future[T]
+}
+
+// NewSharedFuture creates a SharedFuture of fn, which is called with ctx.
+// Awaiters giving up on their own context don't cancel the computation for the others
+func NewSharedFuture[T any](ctx context.Context, fn func(context.Context) (T, error)) *SharedFuture[T] {
+	return &SharedFuture[T]{ctx: ctx, fn: fn}
+}
+
+// Await starts the computation if needed and blocks until its result is available or ctx is done
+func (s *SharedFuture[T]) Await(ctx context.Context) (T, error) {
+	s.once.Do(func() {
+		s.future = Async(s.ctx, s.fn)
+	})
+	return s.future.Await(ctx)
+}
+
+// AsyncMap starts fn for each value of in as soon as it arrives,
+// emitting the Future of each call in the order of in
+func AsyncMap[T, U any](ctx context.Context, in <-chan T, fn func(context.Context, T) (U, error)) <-chan *Future[U] {
+	return AsyncMapLimit(ctx, in, 0, fn)
+}
+
+// AsyncMapLimit is AsyncMap with at most limit calls of fn in flight.
+// A limit smaller than 1 doesn't bound the calls
+func AsyncMapLimit[T, U any](ctx context.Context, in <-chan T, limit int, fn func(context.Context, T) (U, error)) <-chan *Future[U] {
+	futures := make(chan *Future[U])
+
+	go func() {
+		defer close(futures)
+
+		var sem chan struct{}
+		if limit > 0 {
+			sem = make(chan struct{}, limit)
+		}
+
+		for val := range OrDone(ctx, in) {
+			if sem != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case sem <- struct{}{}:
+				}
+			}
+
+			val := val
+			f := Async(ctx, func(ctx context.Context) (U, error) {
+				if sem != nil {
+					defer func() { <-sem }()
+				}
+				return fn(ctx, val)
+			})
+
+			select {
+			case <-ctx.Done():
+				return
+			case futures <- f:
+			}
+		}
+	}()
+	return futures
+}
@@ -0,0 +1,44 @@
+package gopatterns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharedFutureRunsOnce(t *testing.T) {
+	var calls atomic.Int64
+	errFailed := errors.New("failed")
+	future := NewSharedFuture(context.Background(), func(context.Context) (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 42, errFailed
+	})
+
+	const awaiters = 100
+	var (
+		wg      sync.WaitGroup
+		results [awaiters]int
+		errs    [awaiters]error
+	)
+	wg.Add(awaiters)
+	for i := 0; i < awaiters; i++ {
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = future.Await(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fn ran %d times, want 1", n)
+	}
+	for i := 0; i < awaiters; i++ {
+		if results[i] != 42 || !errors.Is(errs[i], errFailed) {
+			t.Errorf("awaiter %d got (%d, %v), want (42, %v)", i, results[i], errs[i], errFailed)
+		}
+	}
+}
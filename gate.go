@@ -0,0 +1,148 @@
+package gopatterns
+
+import "context"
+
+// SkipUntil discards the values of in until signal fires or closes,
+// then forwards everything that comes afterward
+func SkipUntil[T any](ctx context.Context, in <-chan T, signal <-chan struct{}) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+	skip:
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signal:
+				break skip
+			case _, ok := <-in:
+				if !ok {
+					return
+				}
+			}
+		}
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+		}
+	}()
+	return values
+}
+
+// Pausable forwards the values of in while it isn't paused.
+// Sending false on control pauses it, leaving the values to back up in the producer,
+// and sending true resumes it. Closing control resumes it for good
+func Pausable[T any](ctx context.Context, in <-chan T, control <-chan bool) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		var (
+			paused  bool
+			pending T
+			has     bool
+		)
+		for {
+			var (
+				recv <-chan T
+				out  chan<- T
+			)
+			if !paused {
+				if has {
+					out = values
+				} else {
+					recv = in
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case resume, ok := <-control:
+				if !ok {
+					control, resume = nil, true
+				}
+				paused = !resume
+			case val, ok := <-recv:
+				if !ok {
+					return
+				}
+				pending, has = val, true
+			case out <- pending:
+				has = false
+			}
+		}
+	}()
+	return values
+}
+
+// Gate forwards the values of in only while the latest value sent on open is true,
+// discarding those arriving while it's shut.
+// Unlike Pausable, which holds the producer back, a shut Gate keeps draining in.
+// The gate starts shut and stays in its last state once open closes
+func Gate[T any](ctx context.Context, in <-chan T, open <-chan bool) <-chan T {
+	return GateOpts(ctx, in, open, false)
+}
+
+// GateOpts is Gate keeping the values arriving while it's shut when buffer is set,
+// to forward them in order once it opens again.
+// The buffer is unbounded. Values still buffered when in closes are forwarded
+// once the gate opens, or discarded if open closed with the gate shut
+func GateOpts[T any](ctx context.Context, in <-chan T, open <-chan bool, buffer bool) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		var (
+			isOpen bool
+			queue  []T
+		)
+		for in != nil || len(queue) > 0 {
+			if in == nil && !isOpen && open == nil {
+				return
+			}
+
+			var (
+				recv <-chan T
+				out  chan<- T
+				head T
+			)
+			if !isOpen || len(queue) == 0 {
+				recv = in
+			}
+			if isOpen && len(queue) > 0 {
+				out, head = values, queue[0]
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case state, ok := <-open:
+				if !ok {
+					open = nil
+					continue
+				}
+				isOpen = state
+			case val, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+				if isOpen || buffer {
+					queue = append(queue, val)
+				}
+			case out <- head:
+				queue = queue[1:]
+			}
+		}
+	}()
+	return values
+}
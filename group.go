@@ -0,0 +1,45 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+)
+
+// Group tracks the goroutines of a pipeline sharing a cancellable context,
+// so the whole pipeline can be torn down deterministically
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGroup creates a Group whose shared context derives from ctx
+func NewGroup(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Context returns the shared context of the group.
+// Pass it to the stages (OrDone, Take, ...) so they stop on Cancel
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go runs fn on a new goroutine tracked by the group, giving it the shared context
+func (g *Group) Go(fn func(context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn(g.ctx)
+	}()
+}
+
+// Cancel cancels the shared context
+func (g *Group) Cancel() {
+	g.cancel()
+}
+
+// Wait blocks until every goroutine started with Go returned
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
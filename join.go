@@ -0,0 +1,178 @@
+package gopatterns
+
+import (
+	"context"
+	"time"
+)
+
+// Pair holds two values emitted together
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Ordered is satisfied by the types supporting the < operator
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Join correlates a and b, emitting a Pair for each value of a and value of b
+// sharing the same key and arriving less than window apart.
+// Values are kept for window after their arrival, so the memory used is bounded
+// by the number of values both inputs produce within a window.
+// Expired values are evicted as new ones arrive.
+// Closes once both a and b are closed
+func Join[A, B any, K comparable](ctx context.Context, a <-chan A, b <-chan B, keyA func(A) K, keyB func(B) K, window time.Duration) <-chan Pair[A, B] {
+	pairs := make(chan Pair[A, B])
+
+	go func() {
+		defer close(pairs)
+
+		clock := clockFrom(ctx)
+		left := newJoinSide[A, K]()
+		right := newJoinSide[B, K]()
+
+		for a != nil || b != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-a:
+				if !ok {
+					a = nil
+					continue
+				}
+				now := clock.Now()
+				left.evict(now, window)
+				right.evict(now, window)
+
+				key := keyA(val)
+				for _, match := range right.byKey[key] {
+					select {
+					case <-ctx.Done():
+						return
+					case pairs <- Pair[A, B]{First: val, Second: match.val}:
+					}
+				}
+				left.add(key, val, now)
+			case val, ok := <-b:
+				if !ok {
+					b = nil
+					continue
+				}
+				now := clock.Now()
+				left.evict(now, window)
+				right.evict(now, window)
+
+				key := keyB(val)
+				for _, match := range left.byKey[key] {
+					select {
+					case <-ctx.Done():
+						return
+					case pairs <- Pair[A, B]{First: match.val, Second: val}:
+					}
+				}
+				right.add(key, val, now)
+			}
+		}
+	}()
+	return pairs
+}
+
+type joinEntry[T any, K comparable] struct {
+	key K
+	val T
+	at  time.Time
+}
+
+// joinSide holds the values of one Join input still within the window,
+// both in arrival order and grouped by key
+type joinSide[T any, K comparable] struct {
+	arrivals []joinEntry[T, K]
+	byKey    map[K][]joinEntry[T, K]
+}
+
+func newJoinSide[T any, K comparable]() *joinSide[T, K] {
+	return &joinSide[T, K]{byKey: make(map[K][]joinEntry[T, K])}
+}
+
+func (s *joinSide[T, K]) add(key K, val T, at time.Time) {
+	e := joinEntry[T, K]{key: key, val: val, at: at}
+	s.arrivals = append(s.arrivals, e)
+	s.byKey[key] = append(s.byKey[key], e)
+}
+
+func (s *joinSide[T, K]) evict(now time.Time, window time.Duration) {
+	for len(s.arrivals) > 0 && now.Sub(s.arrivals[0].at) >= window {
+		key := s.arrivals[0].key
+		s.arrivals = s.arrivals[1:]
+
+		// the oldest entry of a key is always the first of its group
+		if group := s.byKey[key][1:]; len(group) > 0 {
+			s.byKey[key] = group
+		} else {
+			delete(s.byKey, key)
+		}
+	}
+}
+
+// MergeJoin correlates a and b with a sort-merge join, emitting a Pair for each value of a and value of b
+// sharing the same key: every combination of the values sharing a key is emitted.
+// Both inputs must be sorted by ascending key, otherwise matches are missed.
+// Only one key group of b is held in memory at a time.
+// Closes once either input is closed, as no more matches are possible
+func MergeJoin[A, B any, K Ordered](ctx context.Context, a <-chan A, b <-chan B, keyA func(A) K, keyB func(B) K) <-chan Pair[A, B] {
+	pairs := make(chan Pair[A, B])
+
+	go func() {
+		defer close(pairs)
+
+		va, okA := recvCtx(ctx, a)
+		vb, okB := recvCtx(ctx, b)
+		for okA && okB {
+			ka, kb := keyA(va), keyB(vb)
+			switch {
+			case ka < kb:
+				va, okA = recvCtx(ctx, a)
+				continue
+			case kb < ka:
+				vb, okB = recvCtx(ctx, b)
+				continue
+			}
+
+			// gather the group of b, leaving vb at the first value past it
+			group := []B{vb}
+			for {
+				vb, okB = recvCtx(ctx, b)
+				if !okB || keyB(vb) != ka {
+					break
+				}
+				group = append(group, vb)
+			}
+
+			for okA && keyA(va) == ka {
+				for _, match := range group {
+					select {
+					case <-ctx.Done():
+						return
+					case pairs <- Pair[A, B]{First: va, Second: match}:
+					}
+				}
+				va, okA = recvCtx(ctx, a)
+			}
+		}
+	}()
+	return pairs
+}
+
+// recvCtx receives from c, returning false once c is closed or ctx is done
+func recvCtx[T any](ctx context.Context, c <-chan T) (T, bool) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	case val, ok := <-c:
+		return val, ok
+	}
+}
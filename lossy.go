@@ -0,0 +1,131 @@
+package gopatterns
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Latest always offers the most recently received value of in to the consumer.
+// Values are dropped, not buffered: when the consumer can't keep up
+// the intermediate values are discarded and only the freshest one is kept.
+// The pending value is still delivered once in closes
+func Latest[T any](ctx context.Context, in <-chan T) <-chan T {
+	latest := make(chan T)
+
+	go func() {
+		defer close(latest)
+
+		var (
+			pending T
+			out     chan<- T
+		)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					if out != nil {
+						select {
+						case <-ctx.Done():
+						case out <- pending:
+						}
+					}
+					return
+				}
+				pending, out = v, latest
+			case out <- pending:
+				out = nil
+			}
+		}
+	}()
+	return latest
+}
+
+// TeeLossy forwards the values of in to a reliable and a lossy output.
+// Every value is delivered to the reliable output, applying backpressure as usual,
+// while the lossy output only gets the values it is ready to receive at that moment.
+// The lossy output may miss values, but a slow reader on it never stalls the reliable one
+func TeeLossy[T any](ctx context.Context, in <-chan T) (reliable, lossy <-chan T) {
+	sure := make(chan T)
+	maybe := make(chan T)
+
+	go func() {
+		defer close(sure)
+		defer close(maybe)
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case maybe <- val:
+			default:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case sure <- val:
+			}
+		}
+	}()
+	return sure, maybe
+}
+
+// RingChannel buffers up to capacity values of in for the consumer.
+// When the buffer is full the oldest value is dropped to make room for the newest,
+// so a slow consumer sees the most recent values rather than all of them.
+// The buffered values are still delivered once in closes.
+// A capacity smaller than 1 is treated as 1
+func RingChannel[T any](ctx context.Context, in <-chan T, capacity int) <-chan T {
+	return ringChannel(ctx, in, capacity, false, new(atomic.Int64))
+}
+
+// RingChannelDropNewest is RingChannel keeping the oldest values instead:
+// when the buffer is full the incoming values are dropped until there is room again.
+// The returned function reports how many values were dropped so far
+func RingChannelDropNewest[T any](ctx context.Context, in <-chan T, capacity int) (<-chan T, func() int64) {
+	dropped := new(atomic.Int64)
+	return ringChannel(ctx, in, capacity, true, dropped), dropped.Load
+}
+
+func ringChannel[T any](ctx context.Context, in <-chan T, capacity int, dropNewest bool, dropped *atomic.Int64) <-chan T {
+	if capacity < 1 {
+		capacity = 1
+	}
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		queue := make([]T, 0, capacity)
+		for in != nil || len(queue) > 0 {
+			var (
+				out  chan<- T
+				head T
+			)
+			if len(queue) > 0 {
+				out, head = values, queue[0]
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				if len(queue) == capacity {
+					dropped.Add(1)
+					if dropNewest {
+						continue
+					}
+					queue = queue[1:]
+				}
+				queue = append(queue, val)
+			case out <- head:
+				queue = queue[1:]
+			}
+		}
+	}()
+	return values
+}
@@ -0,0 +1,115 @@
+package gopatterns
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// FanInUntilFirstClose multiplexes channels like FanIn,
+// but stops draining and closes as soon as any of them closes
+func FanInUntilFirstClose[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+
+	multiplexed := make(chan T)
+
+	drain := func(c <-chan T) {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-c:
+				if !ok {
+					cancel()
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case multiplexed <- val:
+				}
+			}
+		}
+	}
+
+	for _, c := range channels {
+		go drain(c)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(multiplexed)
+	}()
+
+	return multiplexed
+}
+
+// PriorityMerge multiplexes channels favoring the earlier ones:
+// when several channels have a value ready, the one listed first is served.
+// The later channels are only served while the earlier ones have nothing ready,
+// so a busy high priority channel can starve the others.
+// Closes once all the channels are closed
+func PriorityMerge[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	merged := make(chan T)
+
+	go func() {
+		defer close(merged)
+
+		open := append([]<-chan T(nil), channels...)
+		// the cases of the blocking wait: the open channels, then ctx
+		cases := make([]reflect.SelectCase, 0, len(open)+1)
+		for _, c := range open {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c)})
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+
+		for len(open) > 0 {
+			val, i, ok := priorityReceive(open, cases)
+			if ctx.Err() != nil {
+				return
+			}
+			if !ok {
+				open = append(open[:i], open[i+1:]...)
+				cases = append(cases[:i], cases[i+1:]...)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case merged <- val:
+			}
+		}
+	}()
+	return merged
+}
+
+// priorityReceive receives from the first channel ready, in order,
+// or waits on cases, the channels followed by the done channel, if none is.
+// Returns the index of the channel received from
+func priorityReceive[T any](channels []<-chan T, cases []reflect.SelectCase) (T, int, bool) {
+	for i, c := range channels {
+		select {
+		case v, ok := <-c:
+			return v, i, ok
+		default:
+		}
+	}
+
+	i, v, ok := reflect.Select(cases)
+	var val T
+	if i == len(channels) {
+		return val, i, false
+	}
+	if ok {
+		// a nil interface value isn't a T, it stays the zero T
+		val, _ = v.Interface().(T)
+	}
+	return val, i, ok
+}
@@ -0,0 +1,72 @@
+package gopatterns
+
+import "context"
+
+// Item carries a value along with metadata such as trace IDs through a pipeline
+type Item[T any] struct {
+	Value T
+	Meta  map[string]any
+}
+
+// WithMeta lifts the values of in into Items, with the metadata returned by meta.
+// A nil meta function gives every Item an empty metadata map
+func WithMeta[T any](ctx context.Context, in <-chan T, meta func(T) map[string]any) <-chan Item[T] {
+	items := make(chan Item[T])
+
+	go func() {
+		defer close(items)
+
+		for val := range OrDone(ctx, in) {
+			item := Item[T]{Value: val}
+			if meta != nil {
+				item.Meta = meta(val)
+			}
+			if item.Meta == nil {
+				item.Meta = make(map[string]any)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case items <- item:
+			}
+		}
+	}()
+	return items
+}
+
+// StripMeta lowers the Items of in back to their values, dropping the metadata
+func StripMeta[T any](ctx context.Context, in <-chan Item[T]) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		for item := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- item.Value:
+			}
+		}
+	}()
+	return values
+}
+
+// MapMeta applies fn to the value of each Item, passing its metadata through untouched
+func MapMeta[T, U any](ctx context.Context, in <-chan Item[T], fn func(T) U) <-chan Item[U] {
+	items := make(chan Item[U])
+
+	go func() {
+		defer close(items)
+
+		for item := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case items <- Item[U]{Value: fn(item.Value), Meta: item.Meta}:
+			}
+		}
+	}()
+	return items
+}
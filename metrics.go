@@ -0,0 +1,114 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Registry holds named Counters measuring the values flowing through stages.
+// It's safe for concurrent use
+type Registry struct {
+	mu       sync.Mutex
+	counters map[string]*Counter
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{counters: make(map[string]*Counter)}
+}
+
+// Stage returns the Counter named name, creating it if needed
+func (r *Registry) Stage(name string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[name]
+	if !ok {
+		c = &Counter{}
+		r.counters[name] = c
+	}
+	return c
+}
+
+// StageMetrics is the state of a Counter at the time of a snapshot
+type StageMetrics struct {
+	Count      int64
+	AvgLatency time.Duration
+}
+
+// Snapshot returns the metrics of every stage by name.
+// The count and latency of each stage are read together so they always match,
+// but the stages are read one after the other: stages still running may have moved on
+// between two reads, so the snapshot isn't consistent across stages
+func (r *Registry) Snapshot() map[string]StageMetrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := make(map[string]StageMetrics, len(r.counters))
+	for name, c := range r.counters {
+		snapshot[name] = c.Metrics()
+	}
+	return snapshot
+}
+
+// Counter counts the values of a stage and their cumulative latency.
+// It's safe for concurrent use
+type Counter struct {
+	mu    sync.Mutex
+	count int64
+	total time.Duration
+}
+
+// Observe records a value that took latency to go through the stage
+func (c *Counter) Observe(latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count++
+	c.total += latency
+}
+
+// Metrics returns the current count and average latency
+func (c *Counter) Metrics() StageMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := StageMetrics{Count: c.count}
+	if c.count > 0 {
+		m.AvgLatency = c.total / time.Duration(c.count)
+	}
+	return m
+}
+
+// Metered forwards the values of in, recording each of them in c
+// with the time it took the downstream to receive it, as Monitored measures it
+func Metered[T any](ctx context.Context, c *Counter, in <-chan T) <-chan T {
+	return measureSends(ctx, in, func(waited time.Duration, sent bool) {
+		if sent {
+			c.Observe(waited)
+		}
+	})
+}
+
+// MeteredMap applies fn to every value of in, recording each call in c with its duration
+func MeteredMap[T, U any](ctx context.Context, c *Counter, in <-chan T, fn func(T) U) <-chan U {
+	results := make(chan U)
+
+	go func() {
+		defer close(results)
+
+		for val := range OrDone(ctx, in) {
+			start := time.Now()
+			res := fn(val)
+			c.Observe(time.Since(start))
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
@@ -0,0 +1,58 @@
+package gopatterns
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Stats reports the activity of a Monitored stage
+type Stats struct {
+	// Forwarded is the number of values sent downstream
+	Forwarded int64
+	// Blocked is the cumulative time spent waiting on the downstream to receive
+	Blocked time.Duration
+}
+
+// Monitored forwards the values of in while measuring how long it waits on the consumer.
+// The returned function reports the current Stats and is safe to call while the stage runs
+func Monitored[T any](ctx context.Context, in <-chan T) (<-chan T, func() Stats) {
+	var forwarded, blocked atomic.Int64
+	values := measureSends(ctx, in, func(waited time.Duration, sent bool) {
+		blocked.Add(int64(waited))
+		if sent {
+			forwarded.Add(1)
+		}
+	})
+
+	stats := func() Stats {
+		return Stats{
+			Forwarded: forwarded.Load(),
+			Blocked:   time.Duration(blocked.Load()),
+		}
+	}
+	return values, stats
+}
+
+// measureSends forwards the values of in, calling observe with the time each send waited on the downstream.
+// sent is false for the send interrupted by ctx
+func measureSends[T any](ctx context.Context, in <-chan T, observe func(waited time.Duration, sent bool)) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		for val := range OrDone(ctx, in) {
+			start := time.Now()
+
+			select {
+			case <-ctx.Done():
+				observe(time.Since(start), false)
+				return
+			case values <- val:
+			}
+			observe(time.Since(start), true)
+		}
+	}()
+	return values
+}
@@ -0,0 +1,91 @@
+package gopatterns
+
+import "context"
+
+// NotificationKind tells which event of a stream a Notification stands for
+type NotificationKind int
+
+const (
+	// KindNext is a value
+	KindNext NotificationKind = iota
+	// KindError is an error
+	KindError
+	// KindComplete is the end of the stream
+	KindComplete
+)
+
+// Notification is an event in the lifecycle of a stream, turned into data
+type Notification[T any] struct {
+	Kind  NotificationKind
+	Value T
+	Err   error
+}
+
+// Materialize turns the values and errors of in into Notifications,
+// followed by a KindComplete one once in closes.
+// No KindComplete Notification is emitted if ctx is done first
+func Materialize[T any](ctx context.Context, in <-chan Result[T]) <-chan Notification[T] {
+	notifications := make(chan Notification[T])
+
+	go func() {
+		defer close(notifications)
+
+		for {
+			var n Notification[T]
+
+			select {
+			case <-ctx.Done():
+				return
+			case res, ok := <-in:
+				switch {
+				case !ok:
+					n.Kind = KindComplete
+				case res.Err != nil:
+					n.Kind, n.Err = KindError, res.Err
+				default:
+					n.Kind, n.Value = KindNext, res.Value
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case notifications <- n:
+			}
+
+			if n.Kind == KindComplete {
+				return
+			}
+		}
+	}()
+	return notifications
+}
+
+// Dematerialize turns Notifications back into Results,
+// closing at the first KindComplete Notification or once in closes
+func Dematerialize[T any](ctx context.Context, in <-chan Notification[T]) <-chan Result[T] {
+	results := make(chan Result[T])
+
+	go func() {
+		defer close(results)
+
+		for n := range OrDone(ctx, in) {
+			var res Result[T]
+			switch n.Kind {
+			case KindComplete:
+				return
+			case KindError:
+				res.Err = n.Err
+			default:
+				res.Value = n.Value
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
@@ -0,0 +1,220 @@
+package gopatterns
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ParallelFilter forwards the values of in satisfying pred,
+// evaluating pred on workers goroutines.
+// The values are forwarded in the order they are evaluated, not the order they came in
+func ParallelFilter[T any](ctx context.Context, in <-chan T, workers int, pred func(T) bool) <-chan T {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	kept := make(chan T)
+
+	filter := func() {
+		defer wg.Done()
+		for val := range OrDone(ctx, in) {
+			if !pred(val) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case kept <- val:
+			}
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		go filter()
+	}
+
+	go func() {
+		wg.Wait()
+		close(kept)
+	}()
+
+	return kept
+}
+
+// ParallelFilterOrdered is ParallelFilter preserving the order of in.
+// At most workers values are evaluated ahead of the slowest pending one.
+// Like OrderedMap, which it's built on, the output closes only after the calls of pred in flight returned
+func ParallelFilterOrdered[T any](ctx context.Context, in <-chan T, workers int, pred func(T) bool) <-chan T {
+	evaluated := OrderedMap(ctx, in, workers, func(_ context.Context, val T) Pair[T, bool] {
+		return Pair[T, bool]{First: val, Second: pred(val)}
+	})
+	kept := make(chan T)
+
+	go func() {
+		defer close(kept)
+
+		// evaluated is drained even once ctx is done, to close only after it
+		for p := range evaluated {
+			if !p.Second {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+			case kept <- p.First:
+			}
+		}
+	}()
+
+	return kept
+}
+
+// ForkJoin solves task by divide and conquer, running up to GOMAXPROCS subtasks in parallel.
+// See ForkJoinLimit
+func ForkJoin[T, R any](ctx context.Context, task T, split func(T) []T, solve func(T) R, combine func([]R) R) R {
+	return ForkJoinLimit(ctx, task, runtime.GOMAXPROCS(0), split, solve, combine)
+}
+
+// ForkJoinLimit recursively splits task until split returns no subtask,
+// solves these leaves and combines the results of each level.
+// At most limit extra goroutines run at once: when none is available
+// a subtask is processed on the goroutine that split it.
+// A limit smaller than 1 processes everything sequentially.
+// Once ctx is done the remaining tasks aren't processed and yield the zero value
+func ForkJoinLimit[T, R any](ctx context.Context, task T, limit int, split func(T) []T, solve func(T) R, combine func([]R) R) R {
+	if limit < 0 {
+		limit = 0
+	}
+	sem := make(chan struct{}, limit)
+
+	var fork func(T) R
+	fork = func(task T) R {
+		if ctx.Err() != nil {
+			var zero R
+			return zero
+		}
+
+		subtasks := split(task)
+		if len(subtasks) == 0 {
+			return solve(task)
+		}
+
+		results := make([]R, len(subtasks))
+		var wg sync.WaitGroup
+		for i, sub := range subtasks {
+			select {
+			case sem <- struct{}{}:
+				wg.Add(1)
+				go func(i int, sub T) {
+					defer wg.Done()
+					defer func() { <-sem }()
+					results[i] = fork(sub)
+				}(i, sub)
+			default:
+				results[i] = fork(sub)
+			}
+		}
+		wg.Wait()
+
+		return combine(results)
+	}
+
+	return fork(task)
+}
+
+// OrderedMap applies fn to the values of in on workers goroutines,
+// emitting the results in the order of in.
+// Once ctx is done no new value is read, and the output closes
+// only after the calls in flight returned, their results being discarded
+func OrderedMap[T, U any](ctx context.Context, in <-chan T, workers int, fn func(context.Context, T) U) <-chan U {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		val T
+		res chan U
+	}
+
+	jobs := make(chan job)
+	pending := make(chan job, workers)
+	results := make(chan U)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+
+		for val := range OrDone(ctx, in) {
+			j := job{val: val, res: make(chan U, 1)}
+
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- j:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- j:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.res <- fn(ctx, j.val)
+			}
+		}()
+	}
+
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+
+		for j := range pending {
+			var res U
+			select {
+			case <-ctx.Done():
+				return
+			case res = <-j.res:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+
+	go func() {
+		<-collected
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// RoundTrip serves the requests with handler one at a time, emitting the responses in order.
+// Once ctx is done no new request is accepted, and the output closes
+// after the request in flight was handled, its response being discarded
+func RoundTrip[Req, Resp any](ctx context.Context, requests <-chan Req, handler func(context.Context, Req) Resp) <-chan Resp {
+	return OrderedMap(ctx, requests, 1, handler)
+}
+
+// RoundTripParallel is RoundTrip serving up to workers requests at once,
+// still emitting the responses in the order of the requests
+func RoundTripParallel[Req, Resp any](ctx context.Context, requests <-chan Req, workers int, handler func(context.Context, Req) Resp) <-chan Resp {
+	return OrderedMap(ctx, requests, workers, handler)
+}
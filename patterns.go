@@ -6,6 +6,7 @@ package gopatterns
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // Confine executes sequentially N functions.
@@ -64,6 +65,40 @@ func Take[T any](ctx context.Context, stream <-chan T, num int) <-chan T {
 	return taken
 }
 
+// TakeDeadline forwards the values of stream until the deadline of ctx, if any, or until it is cancelled.
+// As a context is done once its deadline passed, this is OrDone time-boxed by ctx
+func TakeDeadline[T any](ctx context.Context, stream <-chan T) <-chan T {
+	return OrDone(ctx, stream)
+}
+
+// TakeUntilValue forwards the values of stream until it receives sentinel, then closes.
+// The sentinel itself is forwarded only if inclusive is set
+func TakeUntilValue[T comparable](ctx context.Context, stream <-chan T, sentinel T, inclusive bool) <-chan T {
+	taken := make(chan T)
+
+	go func() {
+		defer close(taken)
+
+		for val := range OrDone(ctx, stream) {
+			if val == sentinel && !inclusive {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case taken <- val:
+			}
+
+			if val == sentinel {
+				return
+			}
+		}
+	}()
+
+	return taken
+}
+
 // FanIn drains in parallel a number of channels [multiplex].
 // Returns the values in a single channel
 func FanIn[T any](ctx context.Context, channels ...<-chan T) <-chan T {
@@ -120,20 +155,37 @@ func OrDone[T any](ctx context.Context, c <-chan T) <-chan T {
 	return values
 }
 
-// Tee receives T values from in and forwards them to left and right channels
+// Tee receives T values from in and forwards them to left and right channels.
+// Both channels get every value until ctx is done. No new value is delivered after that,
+// but the value being delivered at that moment may have reached only one of them
 func Tee[T any](ctx context.Context, in <-chan T) (_, _ <-chan T) {
-	left := make(chan T)
-	right := make(chan T)
+	return TeeBuffered(ctx, in, 0)
+}
+
+// TeeBuffered is a Tee whose outputs can each hold buf values,
+// so a temporarily slow reader doesn't stall the other one.
+// Once a buffer is full the backpressure applies again.
+// A negative buf is treated as 0
+func TeeBuffered[T any](ctx context.Context, in <-chan T, buf int) (_, _ <-chan T) {
+	if buf < 0 {
+		buf = 0
+	}
+	left := make(chan T, buf)
+	right := make(chan T, buf)
 	go func() {
 		defer close(left)
 		defer close(right)
 
 		for val := range OrDone(ctx, in) {
+			if ctx.Err() != nil {
+				return
+			}
 			var out1, out2 = left, right
 
 			for i := 0; i < 2; i++ {
 				select {
 				case <-ctx.Done():
+					return
 				case out1 <- val:
 					out1 = nil
 				case out2 <- val:
@@ -208,3 +260,151 @@ func Or[T any](channels ...<-chan T) <-chan T {
 
 	return orDone
 }
+
+// ApplyStages threads source through each of the stages in order.
+// Every stage gets ctx, so cancelling it stops the whole composed pipeline
+func ApplyStages[T any](ctx context.Context, source <-chan T, stages ...func(context.Context, <-chan T) <-chan T) <-chan T {
+	stream := source
+	for _, stage := range stages {
+		stream = stage(ctx, stream)
+	}
+	return stream
+}
+
+// OrTimeout will close the returned channel once any of the given channels closes or sends something,
+// once timeout elapsed or once ctx is done, whichever comes first
+func OrTimeout[T any](ctx context.Context, timeout time.Duration, channels ...<-chan T) <-chan T {
+	orDone := make(chan T)
+	go func() {
+		defer close(orDone)
+
+		timer := clockFrom(ctx).NewTimer(timeout)
+		defer timer.Stop()
+
+		// releases the goroutines of Or when returning on the timeout or ctx
+		stop := make(chan T)
+		defer close(stop)
+
+		select {
+		case <-Or(append(channels[:len(channels):len(channels)], stop)...):
+		case <-timer.C():
+		case <-ctx.Done():
+		}
+	}()
+
+	return orDone
+}
+
+// StartWith emits the prefix values, then forwards the values of in.
+// Closes when in closes
+func StartWith[T any](ctx context.Context, in <-chan T, prefix ...T) <-chan T {
+	values := make(chan T)
+	go func() {
+		defer close(values)
+
+		for _, v := range prefix {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- v:
+			}
+		}
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+		}
+	}()
+
+	return values
+}
+
+// EndWith forwards the values of in, then emits the suffix values once in closes.
+// The suffix is skipped if ctx is done first
+func EndWith[T any](ctx context.Context, in <-chan T, suffix ...T) <-chan T {
+	values := make(chan T)
+	go func() {
+		defer close(values)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					for _, v := range suffix {
+						select {
+						case <-ctx.Done():
+							return
+						case values <- v:
+						}
+					}
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case values <- val:
+				}
+			}
+		}
+	}()
+
+	return values
+}
+
+// Unfold generates values from a state, starting with seed.
+// fn returns the value to emit, the next state and whether to continue: the value is emitted
+// only if it returns true, and generation stops at the first false
+func Unfold[S, T any](ctx context.Context, seed S, fn func(S) (T, S, bool)) <-chan T {
+	values := make(chan T)
+	go func() {
+		defer close(values)
+
+		state := seed
+		for ctx.Err() == nil {
+			val, next, ok := fn(state)
+			if !ok {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+			state = next
+		}
+	}()
+
+	return values
+}
+
+// MapNonZero applies fn to the values of in, skipping those equal to the zero value of T
+// such as 0, "" or nil, which are dropped without calling fn.
+// The comparison is ==, so a struct is only skipped if all its fields are zero
+func MapNonZero[T comparable, U any](ctx context.Context, in <-chan T, fn func(T) U) <-chan U {
+	mapped := make(chan U)
+
+	go func() {
+		defer close(mapped)
+
+		var zero T
+		for val := range OrDone(ctx, in) {
+			if val == zero {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case mapped <- fn(val):
+			}
+		}
+	}()
+	return mapped
+}
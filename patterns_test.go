@@ -0,0 +1,32 @@
+package gopatterns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTeeCancelMidDelivery(t *testing.T) {
+	src, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	left, right := Tee(ctx, Repeat(src, 1, 2, 3))
+
+	// only left is read, leaving the first value half delivered
+	if v := <-left; v != 1 {
+		t.Fatalf("left got %d, want 1", v)
+	}
+	cancel()
+
+	for name, c := range map[string]<-chan int{"left": left, "right": right} {
+		select {
+		case v, ok := <-c:
+			if ok {
+				t.Errorf("%s got %d after cancellation", name, v)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s not closed after cancellation", name)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package gopatterns
+
+import "context"
+
+// Peekable wraps a channel to look at its next value without consuming it.
+// Peek reads one value ahead from the channel and holds it until Next is called,
+// even if Next is never called.
+// A Peekable isn't safe for concurrent use
+type Peekable[T any] struct {
+	in   <-chan T
+	head T
+	has  bool
+}
+
+// NewPeekable wraps in into a Peekable
+func NewPeekable[T any](in <-chan T) *Peekable[T] {
+	return &Peekable[T]{in: in}
+}
+
+// Peek returns the next value without consuming it.
+// Returns false if the channel is closed or ctx is done
+func (p *Peekable[T]) Peek(ctx context.Context) (T, bool) {
+	if p.has {
+		return p.head, true
+	}
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	case v, ok := <-p.in:
+		if !ok {
+			return v, false
+		}
+		p.head, p.has = v, true
+		return v, true
+	}
+}
+
+// Next consumes and returns the next value.
+// Returns false if the channel is closed or ctx is done
+func (p *Peekable[T]) Next(ctx context.Context) (T, bool) {
+	v, ok := p.Peek(ctx)
+	if ok {
+		var zero T
+		p.head, p.has = zero, false
+	}
+	return v, ok
+}
+
+// RunLen is a value repeated Count consecutive times
+type RunLen[T any] struct {
+	Value T
+	Count int
+}
+
+// RunLength emits the runs of consecutive equal values of in.
+// For a a b c c c it emits {a 2} {b 1} {c 3}, the last run being flushed when in closes
+func RunLength[T comparable](ctx context.Context, in <-chan T) <-chan RunLen[T] {
+	runs := make(chan RunLen[T])
+
+	go func() {
+		defer close(runs)
+
+		values := NewPeekable(in)
+		for {
+			val, ok := values.Next(ctx)
+			if !ok {
+				return
+			}
+
+			run := RunLen[T]{Value: val, Count: 1}
+			for {
+				next, ok := values.Peek(ctx)
+				if !ok || next != val {
+					break
+				}
+				values.Next(ctx)
+				run.Count++
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case runs <- run:
+			}
+		}
+	}()
+	return runs
+}
@@ -0,0 +1,89 @@
+package gopatterns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrPoolClosed is returned when submitting to a Pool that is shut down
+var ErrPoolClosed = errors.New("pool is shut down")
+
+// Pool runs the submitted tasks on a fixed set of worker goroutines
+type Pool struct {
+	tasks   chan func()
+	quit    chan struct{}
+	discard atomic.Bool
+
+	mu     sync.RWMutex
+	closed bool
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewPool starts a Pool of size workers, queueing up to size pending tasks.
+// A size smaller than 1 is treated as 1
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	p := &Pool{
+		tasks: make(chan func(), size),
+		quit:  make(chan struct{}),
+	}
+
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		if p.discard.Load() {
+			continue
+		}
+		task()
+	}
+}
+
+// Submit queues task, blocking while the queue is full.
+// Returns ctx.Err() if ctx is done first, or ErrPoolClosed once the pool is shut down
+func (p *Pool) Submit(ctx context.Context, task func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.quit:
+		return ErrPoolClosed
+	case p.tasks <- task:
+		return nil
+	}
+}
+
+// Shutdown stops accepting tasks and waits for the workers to exit.
+// With drain the queued tasks are still run, otherwise they are discarded.
+// Tasks already running are always waited for
+func (p *Pool) Shutdown(drain bool) {
+	p.once.Do(func() {
+		p.discard.Store(!drain)
+		close(p.quit)
+
+		p.mu.Lock()
+		p.closed = true
+		close(p.tasks)
+		p.mu.Unlock()
+	})
+	p.wg.Wait()
+}
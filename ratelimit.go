@@ -0,0 +1,121 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket hands out rate tokens per second, one at a time.
+// It's safe for concurrent use. Waiters aren't served in any particular order
+type tokenBucket struct {
+	mu     sync.Mutex
+	clock  Clock
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(clock Clock, rate float64) *tokenBucket {
+	return &tokenBucket{clock: clock, rate: rate, tokens: 1, last: clock.Now()}
+}
+
+// wait blocks until a token is available or ctx is done.
+// Returns false if ctx is done first
+func (b *tokenBucket) wait(ctx context.Context) bool {
+	for {
+		b.mu.Lock()
+		now := b.clock.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > 1 {
+			b.tokens = 1
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return true
+		}
+		missing := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := b.clock.NewTimer(missing)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C():
+		}
+	}
+}
+
+// FanInThrottled multiplexes channels like FanIn but emits at most rate values per second overall.
+// The quota is shared by all the channels with no fairness guarantee:
+// under contention a busy channel may get a larger share than the others.
+// A non-positive rate doesn't throttle at all
+func FanInThrottled[T any](ctx context.Context, rate float64, channels ...<-chan T) <-chan T {
+	if rate <= 0 {
+		return FanIn(ctx, channels...)
+	}
+	bucket := newTokenBucket(clockFrom(ctx), rate)
+
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+
+	multiplexed := make(chan T)
+
+	drain := func(c <-chan T) {
+		defer wg.Done()
+		for val := range OrDone(ctx, c) {
+			if !bucket.wait(ctx) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case multiplexed <- val:
+			}
+		}
+	}
+
+	for _, c := range channels {
+		go drain(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(multiplexed)
+	}()
+
+	return multiplexed
+}
+
+// BridgeRateLimited bridges streams like Bridge but emits at most rate values per second overall,
+// the quota being shared by the successive streams. They are still drained one after the other, in order.
+// A non-positive rate doesn't throttle at all
+func BridgeRateLimited[T any](ctx context.Context, streams <-chan <-chan T, rate float64) <-chan T {
+	if rate <= 0 {
+		return Bridge(ctx, streams)
+	}
+	bucket := newTokenBucket(clockFrom(ctx), rate)
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		for val := range Bridge(ctx, streams) {
+			if !bucket.wait(ctx) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+		}
+	}()
+	return values
+}
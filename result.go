@@ -0,0 +1,302 @@
+package gopatterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Result holds either the value produced by a stage or the error that prevented it
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// MapWithin applies fn to every value of in, giving each call at most d to complete.
+// fn receives the per-value context so it can abort once the budget expires.
+// Calls exceeding the budget are emitted as a Result carrying the context error
+func MapWithin[T, U any](ctx context.Context, in <-chan T, d time.Duration, fn func(context.Context, T) U) <-chan Result[U] {
+	results := make(chan Result[U])
+
+	go func() {
+		defer close(results)
+
+		for val := range OrDone(ctx, in) {
+			val := val
+			res := within(ctx, d, func(ctx context.Context) U { return fn(ctx, val) })
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
+
+// within runs fn with a context expiring after d.
+// It returns as soon as the context expires, leaving fn to finish on its own goroutine
+func within[U any](ctx context.Context, d time.Duration, fn func(context.Context) U) Result[U] {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	done := make(chan U, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case v := <-done:
+		return Result[U]{Value: v}
+	case <-ctx.Done():
+		return Result[U]{Err: ctx.Err()}
+	}
+}
+
+// DeadLetter routes the successful results of in to ok and the failed ones to dead.
+// Both channels close when in closes.
+// Both channels must be consumed: a value waiting on one of them blocks the other
+func DeadLetter[T any](ctx context.Context, in <-chan Result[T]) (ok <-chan T, dead <-chan Result[T]) {
+	values := make(chan T)
+	failures := make(chan Result[T])
+
+	go func() {
+		defer close(values)
+		defer close(failures)
+
+		for res := range OrDone(ctx, in) {
+			if res.Err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case failures <- res:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- res.Value:
+			}
+		}
+	}()
+	return values, failures
+}
+
+// RetryStage applies fn to every value of in, calling it up to attempts times until it succeeds.
+// Emits the Result of the last attempt
+func RetryStage[T, U any](ctx context.Context, in <-chan T, attempts int, fn func(context.Context, T) (U, error)) <-chan Result[U] {
+	return RetryStageBackoff(ctx, in, attempts, nil, fn)
+}
+
+// RetryStageBackoff is RetryStage waiting backoff(n) before the nth retry, n starting at 1.
+// A nil backoff retries immediately.
+// A done ctx aborts the retries, including while waiting
+func RetryStageBackoff[T, U any](ctx context.Context, in <-chan T, attempts int, backoff func(n int) time.Duration, fn func(context.Context, T) (U, error)) <-chan Result[U] {
+	if attempts < 1 {
+		attempts = 1
+	}
+	results := make(chan Result[U])
+
+	go func() {
+		defer close(results)
+
+		for val := range OrDone(ctx, in) {
+			var res Result[U]
+			for n := 0; n < attempts; n++ {
+				if n > 0 && backoff != nil {
+					timer := clockFrom(ctx).NewTimer(backoff(n))
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return
+					case <-timer.C():
+					}
+				}
+
+				res.Value, res.Err = fn(ctx, val)
+				if res.Err == nil || ctx.Err() != nil {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
+
+// ErrCircuitOpen is the error of the results MapBreaker short-circuits
+var ErrCircuitOpen = errors.New("circuit open")
+
+// MapBreaker applies fn to every value of in behind a circuit breaker.
+// Once the error rate of the last window calls exceeds threshold the circuit opens,
+// and the following values are emitted as ErrCircuitOpen results without calling fn.
+// Skipped calls count as successes in the window, so the circuit closes again
+// once enough of them pushed the errors out and the rate is back to threshold or below
+func MapBreaker[T, U any](ctx context.Context, in <-chan T, fn func(T) (U, error), threshold float64, window int) <-chan Result[U] {
+	return MapBreakerNotify(ctx, in, fn, threshold, window, nil)
+}
+
+// MapBreakerNotify is MapBreaker calling onChange each time the circuit opens or closes.
+// onChange runs on the stage goroutine
+func MapBreakerNotify[T, U any](ctx context.Context, in <-chan T, fn func(T) (U, error), threshold float64, window int, onChange func(open bool)) <-chan Result[U] {
+	if window < 1 {
+		window = 1
+	}
+	results := make(chan Result[U])
+
+	go func() {
+		defer close(results)
+
+		var (
+			calls    = make([]bool, window)
+			next     int
+			recorded int
+			failures int
+			open     bool
+		)
+		record := func(failed bool) {
+			if recorded == window {
+				if calls[next] {
+					failures--
+				}
+			} else {
+				recorded++
+			}
+			calls[next] = failed
+			if failed {
+				failures++
+			}
+			next = (next + 1) % window
+
+			tripped := recorded == window && float64(failures)/float64(window) > threshold
+			if tripped != open {
+				open = tripped
+				if onChange != nil {
+					onChange(open)
+				}
+			}
+		}
+
+		for val := range OrDone(ctx, in) {
+			var res Result[U]
+			if open {
+				res.Err = ErrCircuitOpen
+				record(false)
+			} else {
+				res.Value, res.Err = fn(val)
+				record(res.Err != nil)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case results <- res:
+			}
+		}
+	}()
+	return results
+}
+
+// BridgeResults bridges a sequence of fallible streams into a single channel.
+// An error Result is forwarded like any other value: it doesn't abort the bridge,
+// and the current stream keeps being drained until it closes before moving to the next one
+func BridgeResults[T any](ctx context.Context, streams <-chan <-chan Result[T]) <-chan Result[T] {
+	return Bridge(ctx, streams)
+}
+
+// FanInResults multiplexes fallible streams like FanIn, forwarding errors as any other value.
+// Every channel is drained until it closes, so nothing leaks as long as the producers close them
+func FanInResults[T any](ctx context.Context, channels ...<-chan Result[T]) <-chan Result[T] {
+	return FanIn(ctx, channels...)
+}
+
+// FanInResultsFailFast is FanInResults calling cancel right after forwarding the first error.
+// cancel is expected to cancel ctx, which should also be the context of the producers:
+// the remaining channels are then abandoned, so this mode only leaks nothing
+// if the producers stop once ctx is done
+func FanInResultsFailFast[T any](ctx context.Context, cancel context.CancelFunc, channels ...<-chan Result[T]) <-chan Result[T] {
+	var wg sync.WaitGroup
+	wg.Add(len(channels))
+
+	multiplexed := make(chan Result[T])
+
+	drain := func(c <-chan Result[T]) {
+		defer wg.Done()
+		for res := range OrDone(ctx, c) {
+			select {
+			case <-ctx.Done():
+				return
+			case multiplexed <- res:
+			}
+
+			if res.Err != nil {
+				cancel()
+				return
+			}
+		}
+	}
+
+	for _, c := range channels {
+		go drain(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(multiplexed)
+	}()
+
+	return multiplexed
+}
+
+// PanicError is the error of a Result whose function panicked
+type PanicError struct {
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverMap applies fn to every value of in, turning a panic of fn into a *PanicError result.
+// Only the panics of fn are recovered, not those of the channel operations
+func RecoverMap[T, U any](ctx context.Context, in <-chan T, fn func(T) U) <-chan Result[U] {
+	results := make(chan Result[U])
+
+	call := func(val T) (res Result[U]) {
+		defer func() {
+			if r := recover(); r != nil {
+				res = Result[U]{Err: &PanicError{Value: r}}
+			}
+		}()
+		return Result[U]{Value: fn(val)}
+	}
+
+	go func() {
+		defer close(results)
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case results <- call(val):
+			}
+		}
+	}()
+	return results
+}
@@ -0,0 +1,68 @@
+package gopatterns
+
+import (
+	"context"
+	"sync"
+)
+
+// Ring is a bounded history keeping the last values pushed into it.
+// It's safe for concurrent use
+type Ring[T any] struct {
+	mu     sync.Mutex
+	values []T
+	next   int
+	full   bool
+}
+
+// NewRing creates a Ring holding up to capacity values.
+// A capacity smaller than 1 is treated as 1
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Ring[T]{values: make([]T, capacity)}
+}
+
+// Push adds v to the ring, overwriting the oldest value once the ring is full
+func (r *Ring[T]) Push(v T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[r.next] = v
+	r.next = (r.next + 1) % len(r.values)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Snapshot returns a copy of the current contents, oldest first
+func (r *Ring[T]) Snapshot() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]T(nil), r.values[:r.next]...)
+	}
+	snapshot := make([]T, 0, len(r.values))
+	snapshot = append(snapshot, r.values[r.next:]...)
+	return append(snapshot, r.values[:r.next]...)
+}
+
+// Replay emits the contents of the ring at the time of the call, oldest first
+func (r *Ring[T]) Replay(ctx context.Context) <-chan T {
+	snapshot := r.Snapshot()
+	replay := make(chan T)
+
+	go func() {
+		defer close(replay)
+
+		for _, v := range snapshot {
+			select {
+			case <-ctx.Done():
+				return
+			case replay <- v:
+			}
+		}
+	}()
+	return replay
+}
@@ -0,0 +1,93 @@
+package gopatterns
+
+import (
+	"context"
+	"fmt"
+)
+
+// Route sends each value of in to the output router returns the index of, among n outputs.
+// Values routed out of range are dropped.
+// The values are sent one at a time: a slow output holds back the others.
+// All the outputs close when in closes
+func Route[T any](ctx context.Context, in <-chan T, router func(T) int, n int) []<-chan T {
+	return RouteOpts(ctx, in, router, n, false)
+}
+
+// RouteOpts is Route panicking on a value routed out of range when strict is set,
+// instead of dropping it
+func RouteOpts[T any](ctx context.Context, in <-chan T, router func(T) int, n int, strict bool) []<-chan T {
+	if n < 0 {
+		n = 0
+	}
+	branches := make([]chan T, n)
+	outputs := make([]<-chan T, n)
+	for i := range branches {
+		branches[i] = make(chan T)
+		outputs[i] = branches[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, b := range branches {
+				close(b)
+			}
+		}()
+
+		for val := range OrDone(ctx, in) {
+			i := router(val)
+			if i < 0 || i >= n {
+				if strict {
+					panic(fmt.Sprintf("gopatterns: Route index %d out of range [0, %d)", i, n))
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case branches[i] <- val:
+			}
+		}
+	}()
+	return outputs
+}
+
+// Transpose splits the rows of in into cols columns, sending the j-th value of each row on the j-th output.
+// Rows shorter than cols are padded with the zero value, the values of longer rows past cols are dropped.
+// The outputs advance in lockstep, one row at a time: a slow column holds back the others.
+// All the outputs close when in closes
+func Transpose[T any](ctx context.Context, in <-chan []T, cols int) []<-chan T {
+	if cols < 0 {
+		cols = 0
+	}
+	columns := make([]chan T, cols)
+	outputs := make([]<-chan T, cols)
+	for j := range columns {
+		columns[j] = make(chan T)
+		outputs[j] = columns[j]
+	}
+
+	go func() {
+		defer func() {
+			for _, c := range columns {
+				close(c)
+			}
+		}()
+
+		for row := range OrDone(ctx, in) {
+			for j, c := range columns {
+				var val T
+				if j < len(row) {
+					val = row[j]
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case c <- val:
+				}
+			}
+		}
+	}()
+	return outputs
+}
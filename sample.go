@@ -0,0 +1,123 @@
+package gopatterns
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Reservoir drains in and returns a uniform random sample of up to k of its values.
+// It only keeps k values in memory (Algorithm R).
+// All the values are returned if in produces fewer than k, and none if k is smaller than 1
+func Reservoir[T any](ctx context.Context, in <-chan T, k int) []T {
+	return ReservoirRand(ctx, in, k, rand.NewSource(time.Now().UnixNano()))
+}
+
+// ReservoirRand is Reservoir drawing its randomness from src.
+// A fixed seed makes the sample deterministic
+func ReservoirRand[T any](ctx context.Context, in <-chan T, k int, src rand.Source) []T {
+	if k <= 0 {
+		for range OrDone(ctx, in) {
+		}
+		return nil
+	}
+
+	rng := rand.New(src)
+	sample := make([]T, 0, k)
+
+	i := 0
+	for val := range OrDone(ctx, in) {
+		if i < k {
+			sample = append(sample, val)
+		} else if j := rng.Intn(i + 1); j < k {
+			sample[j] = val
+		}
+		i++
+	}
+
+	return sample
+}
+
+// Shuffle approximates a random permutation of in with a buffer of bufferSize values:
+// once the buffer is full, each arriving value takes the place of a randomly chosen one, which is emitted.
+// The larger the buffer, the further a value can move from its position.
+// The remaining values are emitted in random order when in closes.
+// The same seeded rng gives the same order, a nil one is seeded with the time.
+// A bufferSize smaller than 1 is treated as 1
+func Shuffle[T any](ctx context.Context, in <-chan T, bufferSize int, rng *rand.Rand) <-chan T {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	shuffled := make(chan T)
+
+	go func() {
+		defer close(shuffled)
+
+		buf := make([]T, 0, bufferSize)
+		emit := func(val T) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case shuffled <- val:
+				return true
+			}
+		}
+
+		for val := range OrDone(ctx, in) {
+			if len(buf) < bufferSize {
+				buf = append(buf, val)
+				continue
+			}
+			j := rng.Intn(bufferSize)
+			out := buf[j]
+			buf[j] = val
+			if !emit(out) {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		rng.Shuffle(len(buf), func(i, j int) { buf[i], buf[j] = buf[j], buf[i] })
+		for _, val := range buf {
+			if !emit(val) {
+				return
+			}
+		}
+	}()
+	return shuffled
+}
+
+// SampleFraction forwards each value of in with probability fraction, independently of the others.
+// The same seeded rng samples the same values, a nil one is seeded with the time.
+// Panics if fraction is outside [0, 1]
+func SampleFraction[T any](ctx context.Context, in <-chan T, fraction float64, rng *rand.Rand) <-chan T {
+	if !(fraction >= 0 && fraction <= 1) {
+		panic("gopatterns: SampleFraction fraction must be in [0, 1]")
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	sampled := make(chan T)
+
+	go func() {
+		defer close(sampled)
+
+		for val := range OrDone(ctx, in) {
+			if rng.Float64() >= fraction {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case sampled <- val:
+			}
+		}
+	}()
+	return sampled
+}
@@ -0,0 +1,155 @@
+package gopatterns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope tracks the named stages of a pipeline sharing a cancellable context,
+// so that once cancelled it can be checked that every one of them actually stopped, and which did not.
+// Stages register with Go, for the goroutines run directly, or Scoped, for the stages returning a channel
+type Scope struct {
+	group   *Group
+	mu      sync.Mutex
+	running map[*scopeEntry]struct{}
+}
+
+type scopeEntry struct {
+	name string
+	done chan struct{}
+}
+
+// StuckError is returned by Scope.Wait, naming the stages still running once it timed out
+type StuckError struct {
+	Stages []string
+}
+
+func (e *StuckError) Error() string {
+	return fmt.Sprintf("stages did not stop: %s", strings.Join(e.Stages, ", "))
+}
+
+// NewScope creates a Scope whose shared context derives from ctx
+func NewScope(ctx context.Context) *Scope {
+	return &Scope{group: NewGroup(ctx), running: make(map[*scopeEntry]struct{})}
+}
+
+// Context returns the shared context of the scope, for its stages to stop on Cancel
+func (s *Scope) Context() context.Context {
+	return s.group.Context()
+}
+
+// Cancel cancels the shared context
+func (s *Scope) Cancel() {
+	s.group.Cancel()
+}
+
+// Go runs fn on a new goroutine registered as name until it returns, giving it the shared context
+func (s *Scope) Go(name string, fn func(context.Context)) {
+	e := s.register(name)
+	s.group.Go(func(ctx context.Context) {
+		defer s.release(e)
+		fn(ctx)
+	})
+}
+
+// Wait blocks until every stage registered stopped, or timeout elapsed.
+// Returns a *StuckError listing the stages still running in the latter case.
+// Wait doesn't cancel the scope: call Cancel, or cancel the parent context, first
+func (s *Scope) Wait(timeout time.Duration) error {
+	deadline := clockFrom(s.Context()).NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		var next *scopeEntry
+		s.mu.Lock()
+		for e := range s.running {
+			next = e
+			break
+		}
+		s.mu.Unlock()
+		if next == nil {
+			return nil
+		}
+
+		select {
+		case <-next.done:
+		case <-deadline.C():
+			return s.stuck()
+		}
+	}
+}
+
+func (s *Scope) register(name string) *scopeEntry {
+	e := &scopeEntry{name: name, done: make(chan struct{})}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running[e] = struct{}{}
+	return e
+}
+
+func (s *Scope) release(e *scopeEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, e)
+	close(e.done)
+}
+
+func (s *Scope) stuck() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.running) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s.running))
+	for e := range s.running {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return &StuckError{Stages: names}
+}
+
+// Scoped registers the stage producing out as name, until it closes out.
+// The stages close their output when their goroutine exits, which is what the scope tracks.
+// The values of out are forwarded unchanged. Once the scope is cancelled the returned channel closes,
+// and out is drained until the stage closes it
+func Scoped[T any](s *Scope, name string, out <-chan T) <-chan T {
+	e := s.register(name)
+	values := make(chan T)
+
+	s.group.Go(func(ctx context.Context) {
+		defer s.release(e)
+
+		// forward reports whether out is still open
+		forward := func() bool {
+			defer close(values)
+			for {
+				select {
+				case <-ctx.Done():
+					return true
+				case val, ok := <-out:
+					if !ok {
+						return false
+					}
+
+					select {
+					case <-ctx.Done():
+						return true
+					case values <- val:
+					}
+				}
+			}
+		}
+		if forward() {
+			for range out {
+			}
+		}
+	})
+	return values
+}
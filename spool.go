@@ -0,0 +1,186 @@
+package gopatterns
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// diskBufferMemory is the number of values DiskBuffer holds in memory before spilling to disk
+const diskBufferMemory = 64
+
+// spoolCompactAt is the size of the records already read back from which a spool compacts its file
+const spoolCompactAt = 64 << 10
+
+// DiskBuffer reads in greedily, holding up to a few values in memory and
+// spilling the rest to a temporary file created in dir, or the default temporary directory if dir is empty.
+// The values are read back and emitted in the order they arrived, whether they spilled or not.
+// The file only holds the values still waiting: the space of those read back is reclaimed as it goes.
+// It's only a buffer, not a durable store: it's removed once the stage closes
+// and the values it held are lost on cancellation or crash.
+// Stops and closes if encode, decode, or writing or reading the file fails, dropping the values buffered.
+// Once values is closed, failure returns the error that stopped it, or nil if in closed or ctx is done
+func DiskBuffer[T any](ctx context.Context, in <-chan T, encode func(T) ([]byte, error), decode func([]byte) (T, error), dir string) (values <-chan T, failure func() error) {
+	out := make(chan T)
+	var (
+		mu      sync.Mutex
+		stopped error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = err
+	}
+
+	go func() {
+		defer close(out)
+
+		s := &spool{dir: dir}
+		defer s.close()
+
+		mem := make([]T, 0, diskBufferMemory)
+		for in != nil || len(mem) > 0 || s.pending > 0 {
+			// move the spilled values back to memory as room frees up there
+			for len(mem) < diskBufferMemory && s.pending > 0 {
+				rec, err := s.read()
+				if err != nil {
+					fail(err)
+					return
+				}
+				val, err := decode(rec)
+				if err != nil {
+					fail(fmt.Errorf("decoding a spilled value: %w", err))
+					return
+				}
+				mem = append(mem, val)
+			}
+
+			var (
+				send chan<- T
+				head T
+			)
+			if len(mem) > 0 {
+				send, head = out, mem[0]
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case val, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				// once spilling, keep every new value behind the spilled ones
+				if len(mem) < diskBufferMemory && s.pending == 0 {
+					mem = append(mem, val)
+					continue
+				}
+				rec, err := encode(val)
+				if err != nil {
+					fail(fmt.Errorf("encoding a value to spill: %w", err))
+					return
+				}
+				if err := s.write(rec); err != nil {
+					fail(err)
+					return
+				}
+			case send <- head:
+				mem = mem[1:]
+			}
+		}
+	}()
+	return out, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return stopped
+	}
+}
+
+// spool is a queue of length prefixed records backed by a temporary file, created on the first write
+type spool struct {
+	dir     string
+	f       *os.File
+	r, w    int64
+	pending int
+}
+
+func (s *spool) write(rec []byte) error {
+	if s.f == nil {
+		f, err := os.CreateTemp(s.dir, "gopatterns-spool-*")
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+
+	buf := make([]byte, 8+len(rec))
+	binary.BigEndian.PutUint64(buf, uint64(len(rec)))
+	copy(buf[8:], rec)
+	if _, err := s.f.WriteAt(buf, s.w); err != nil {
+		return err
+	}
+	s.w += int64(len(buf))
+	s.pending++
+	return nil
+}
+
+func (s *spool) read() ([]byte, error) {
+	var prefix [8]byte
+	if _, err := s.f.ReadAt(prefix[:], s.r); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint64(prefix[:])
+	s.r += int64(len(prefix))
+
+	rec := make([]byte, size)
+	if _, err := s.f.ReadAt(rec, s.r); err != nil {
+		return nil, err
+	}
+	s.r += int64(size)
+	s.pending--
+
+	// reclaim the space once every record was read back,
+	// or once the records read back take more room than those left
+	if s.pending == 0 {
+		s.r, s.w = 0, 0
+		return rec, s.f.Truncate(0)
+	}
+	if s.r >= spoolCompactAt && s.r >= s.w-s.r {
+		return rec, s.compact()
+	}
+	return rec, nil
+}
+
+// compact moves the records left to read to the start of the file and truncates it
+func (s *spool) compact() error {
+	buf := make([]byte, 32<<10)
+	var moved int64
+	for live := s.w - s.r; moved < live; {
+		n := int64(len(buf))
+		if n > live-moved {
+			n = live - moved
+		}
+		if _, err := s.f.ReadAt(buf[:n], s.r+moved); err != nil {
+			return err
+		}
+		// the records are moved toward the start, so the chunk written never overlaps those left to move
+		if _, err := s.f.WriteAt(buf[:n], moved); err != nil {
+			return err
+		}
+		moved += n
+	}
+
+	s.r, s.w = 0, moved
+	return s.f.Truncate(moved)
+}
+
+func (s *spool) close() {
+	if s.f == nil {
+		return
+	}
+	s.f.Close()
+	os.Remove(s.f.Name())
+}
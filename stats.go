@@ -0,0 +1,165 @@
+package gopatterns
+
+import "context"
+
+// Number is satisfied by the integer and floating point types
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// MovingAverage emits the average of the last window values for each value received.
+// Nothing is emitted until window values have arrived.
+// A window smaller than 1 is treated as 1
+func MovingAverage[T Number](ctx context.Context, in <-chan T, window int) <-chan float64 {
+	return movingAverage(ctx, in, window, false)
+}
+
+// MovingAveragePartial is MovingAverage emitting from the first value,
+// averaging over the values received so far until the window is full
+func MovingAveragePartial[T Number](ctx context.Context, in <-chan T, window int) <-chan float64 {
+	return movingAverage(ctx, in, window, true)
+}
+
+func movingAverage[T Number](ctx context.Context, in <-chan T, window int, partial bool) <-chan float64 {
+	if window < 1 {
+		window = 1
+	}
+	averages := make(chan float64)
+
+	go func() {
+		defer close(averages)
+
+		ring := make([]float64, window)
+		var (
+			sum  float64
+			next int
+			n    int
+		)
+		for val := range OrDone(ctx, in) {
+			if n == window {
+				sum -= ring[next]
+			} else {
+				n++
+			}
+			ring[next] = float64(val)
+			sum += ring[next]
+			next = (next + 1) % window
+
+			if n < window && !partial {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case averages <- sum / float64(n):
+			}
+		}
+	}()
+	return averages
+}
+
+// EMA emits the exponential moving average of in for each value received,
+// ema = alpha*value + (1-alpha)*previous, seeded with the first value.
+// Panics if alpha is outside (0, 1]
+func EMA[T Number](ctx context.Context, in <-chan T, alpha float64) <-chan float64 {
+	if alpha <= 0 || alpha > 1 {
+		panic("gopatterns: EMA alpha must be in (0, 1]")
+	}
+	averages := make(chan float64)
+
+	go func() {
+		defer close(averages)
+
+		var (
+			ema    float64
+			seeded bool
+		)
+		for val := range OrDone(ctx, in) {
+			if seeded {
+				ema = alpha*float64(val) + (1-alpha)*ema
+			} else {
+				ema, seeded = float64(val), true
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case averages <- ema:
+			}
+		}
+	}()
+	return averages
+}
+
+// WindowStats summarizes the values of a sliding window
+type WindowStats[T Number] struct {
+	Min   T
+	Max   T
+	Mean  float64
+	Count int
+}
+
+// SlidingStats emits the WindowStats of the last window values for each value received.
+// Min and max are tracked with monotonic deques, so each value costs amortized O(1).
+// A window smaller than 1 is treated as 1
+func SlidingStats[T Number](ctx context.Context, in <-chan T, window int) <-chan WindowStats[T] {
+	if window < 1 {
+		window = 1
+	}
+	stats := make(chan WindowStats[T])
+
+	type indexed struct {
+		i int
+		v T
+	}
+
+	go func() {
+		defer close(stats)
+
+		var (
+			ring        = make([]T, window)
+			sum         float64
+			i           int
+			lows, highs []indexed
+		)
+		for val := range OrDone(ctx, in) {
+			if i >= window {
+				sum -= float64(ring[i%window])
+			}
+			ring[i%window] = val
+			sum += float64(val)
+
+			for len(lows) > 0 && lows[len(lows)-1].v >= val {
+				lows = lows[:len(lows)-1]
+			}
+			lows = append(lows, indexed{i, val})
+			for len(highs) > 0 && highs[len(highs)-1].v <= val {
+				highs = highs[:len(highs)-1]
+			}
+			highs = append(highs, indexed{i, val})
+
+			for lows[0].i <= i-window {
+				lows = lows[1:]
+			}
+			for highs[0].i <= i-window {
+				highs = highs[1:]
+			}
+
+			i++
+			count := i
+			if count > window {
+				count = window
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case stats <- WindowStats[T]{Min: lows[0].v, Max: highs[0].v, Mean: sum / float64(count), Count: count}:
+			}
+		}
+	}()
+	return stats
+}
@@ -0,0 +1,155 @@
+package gopatterns
+
+import (
+	"context"
+	"time"
+)
+
+// OnIdle forwards the values of in and signals on the second channel
+// each time no value arrived for idle, repeatedly while the stream stays quiet.
+// At most one signal is kept pending, the extra ones are dropped so signaling never stalls the values.
+// Both channels close when in closes
+func OnIdle[T any](ctx context.Context, in <-chan T, idle time.Duration) (<-chan T, <-chan struct{}) {
+	values := make(chan T)
+	idles := make(chan struct{}, 1)
+
+	go func() {
+		defer close(values)
+		defer close(idles)
+
+		timer := clockFrom(ctx).NewTimer(idle)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C():
+				select {
+				case idles <- struct{}{}:
+				default:
+				}
+				timer.Reset(idle)
+			case val, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case values <- val:
+				}
+
+				resetTimer(timer, idle)
+			}
+		}
+	}()
+	return values, idles
+}
+
+// WithDefault forwards the values of in, emitting def whenever no value arrived for d.
+// Closes when in closes
+func WithDefault[T any](ctx context.Context, in <-chan T, d time.Duration, def T) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		timer := clockFrom(ctx).NewTimer(d)
+		defer timer.Stop()
+
+		for {
+			var val T
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C():
+				val = def
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				val = v
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+			}
+			resetTimer(timer, d)
+		}
+	}()
+	return values
+}
+
+// TimedValue is a value ReplayTimed emits After the previous one
+type TimedValue[T any] struct {
+	V     T
+	After time.Duration
+}
+
+// ReplayTimed emits the values of items in order, each one After the previous emission,
+// the first one After the call. It gives timing sensitive stages a precisely paced input
+func ReplayTimed[T any](ctx context.Context, items []TimedValue[T]) <-chan T {
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		clock := clockFrom(ctx)
+		for _, item := range items {
+			timer := clock.NewTimer(item.After)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case values <- item.V:
+			}
+		}
+	}()
+	return values
+}
+
+// RepeatWithDelay cycles through values like Repeat, waiting delay after each value is received
+// before emitting the next one. The first value is emitted right away.
+// Closes immediately if values is empty
+func RepeatWithDelay[T any](ctx context.Context, delay time.Duration, values ...T) <-chan T {
+	stream := make(chan T)
+
+	go func() {
+		defer close(stream)
+
+		if len(values) == 0 {
+			return
+		}
+		timer := clockFrom(ctx).NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			for _, v := range values {
+				select {
+				case <-ctx.Done():
+					return
+				case <-timer.C():
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case stream <- v:
+				}
+				timer.Reset(delay)
+			}
+		}
+	}()
+	return stream
+}
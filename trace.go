@@ -0,0 +1,55 @@
+package gopatterns
+
+import "context"
+
+// Tracer starts the spans of the Traced stages.
+// It's small enough to be adapted to OpenTelemetry or any other tracing library
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation started by a Tracer
+type Span interface {
+	SetAttribute(key string, value any)
+	End()
+}
+
+type tracerKey struct{}
+
+// WithTracer returns a copy of ctx carrying t, for the Traced stages to use
+func WithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// Traced forwards the values of in within a span named name, started by the Tracer of ctx.
+// The span lasts for the lifetime of the stage and records the number of values
+// forwarded as its "elements" attribute.
+// Without a Tracer in ctx it only forwards the values
+func Traced[T any](ctx context.Context, name string, in <-chan T) <-chan T {
+	tracer, ok := ctx.Value(tracerKey{}).(Tracer)
+	if !ok {
+		return OrDone(ctx, in)
+	}
+	values := make(chan T)
+
+	go func() {
+		defer close(values)
+
+		ctx, span := tracer.Start(ctx, name)
+		var n int64
+		defer func() {
+			span.SetAttribute("elements", n)
+			span.End()
+		}()
+
+		for val := range OrDone(ctx, in) {
+			select {
+			case <-ctx.Done():
+				return
+			case values <- val:
+				n++
+			}
+		}
+	}()
+	return values
+}
@@ -0,0 +1,132 @@
+package gopatterns
+
+import "context"
+
+// Zip pairs the values of a and b in order, closing when either of them closes
+func Zip[A, B any](ctx context.Context, a <-chan A, b <-chan B) <-chan Pair[A, B] {
+	return ZipWith(ctx, a, b, func(a A, b B) Pair[A, B] { return Pair[A, B]{First: a, Second: b} })
+}
+
+// ZipWith combines the values of a and b in order, closing when either of them closes
+func ZipWith[A, B, C any](ctx context.Context, a <-chan A, b <-chan B, combine func(A, B) C) <-chan C {
+	zipped := make(chan C)
+
+	go func() {
+		defer close(zipped)
+
+		for {
+			var (
+				va A
+				vb B
+				ok bool
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case va, ok = <-a:
+				if !ok {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case vb, ok = <-b:
+				if !ok {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case zipped <- combine(va, vb):
+			}
+		}
+	}()
+	return zipped
+}
+
+// ZipN emits a slice holding one value of each channel per round, in the order of channels.
+// Closes when any of them closes
+func ZipN[T any](ctx context.Context, channels ...<-chan T) <-chan []T {
+	zipped := make(chan []T)
+
+	go func() {
+		defer close(zipped)
+
+		if len(channels) == 0 {
+			return
+		}
+
+		for {
+			round := make([]T, len(channels))
+			for i, c := range channels {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-c:
+					if !ok {
+						return
+					}
+					round[i] = v
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case zipped <- round:
+			}
+		}
+	}()
+	return zipped
+}
+
+// CombineLatest emits a Pair each time a or b produces a value,
+// combining it with the latest value of the other one.
+// Nothing is emitted until both produced a value.
+// Closes once both a and b are closed
+func CombineLatest[A, B any](ctx context.Context, a <-chan A, b <-chan B) <-chan Pair[A, B] {
+	combined := make(chan Pair[A, B])
+
+	go func() {
+		defer close(combined)
+
+		var (
+			latest     Pair[A, B]
+			hasA, hasB bool
+		)
+		for a != nil || b != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case va, ok := <-a:
+				if !ok {
+					a = nil
+					continue
+				}
+				latest.First, hasA = va, true
+			case vb, ok := <-b:
+				if !ok {
+					b = nil
+					continue
+				}
+				latest.Second, hasB = vb, true
+			}
+
+			if !hasA || !hasB {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case combined <- latest:
+			}
+		}
+	}()
+	return combined
+}